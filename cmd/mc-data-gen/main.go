@@ -1,13 +1,20 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/reallyoldfogie/mc-data-gen/internal/mcgen"
 )
@@ -15,16 +22,49 @@ import (
 type versionResult struct {
 	version string
 	success bool
+	skipped bool
 	err     error
+	logPath string
 }
 
+// generator holds the settings shared by every version in a run.
+type generator struct {
+	workDir string
+	cfg     *mcgen.Config
+	// force regenerates versions whose manifest says they are up to date.
+	force bool
+	// lock supplies the Fabric versions; offline forbids resolving any
+	// that are missing from it through meta.
+	lock    *mcgen.Lockfile
+	meta    *mcgen.MetaClient
+	offline bool
+}
+
+// basePort is the server port of the first parallel worker; worker i uses
+// basePort+i so concurrent exporter servers don't collide.
+const basePort = 25565
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "update-lock" {
+		updateLock(os.Args[2:])
+		return
+	}
+
 	configPath := flag.String("config", "mc-data-gen.yaml", "path to config file (YAML)")
+	lockFlag := flag.String("lock", "", "lockfile of resolved Fabric versions (default: "+mcgen.DefaultLockfileName+" next to the config)")
+	offline := flag.Bool("offline", false, "use only the lockfile to pick Fabric versions; never query Fabric Meta")
 	workDir := flag.String("work-dir", "./work", "directory for generated per-version Fabric projects")
 	versionsStr := flag.String("versions", "", "comma-separated list of versions to generate (if empty, use all from config)")
 	generateSrc := flag.Bool("generate-src", false, "enable source decompilation and copy to extractedSrc")
+	force := flag.Bool("force", false, "regenerate every version, even when its build manifest shows nothing changed")
+	tailLines := flag.Int("tail", 20, "lines of Gradle output to show in the summary for each failed version")
+	jobs := flag.Int("jobs", 1, "number of versions to generate concurrently; with more than 1, each version logs to <work-dir>/<version>.log")
 	flag.Parse()
 
+	if *jobs < 1 {
+		log.Fatalf("-jobs must be at least 1")
+	}
+
 	cfg, err := mcgen.LoadConfig(*configPath)
 	if err != nil {
 		log.Fatalf("load config: %v", err)
@@ -34,13 +74,18 @@ func main() {
 		log.Fatalf("create work dir: %v", err)
 	}
 
-	// Filter versions if specified
-	versionsToProcess := cfg.Versions
-	if *versionsStr != "" {
-		versionsToProcess = strings.Split(strings.TrimSpace(*versionsStr), ",")
-		for i := range versionsToProcess {
-			versionsToProcess[i] = strings.TrimSpace(versionsToProcess[i])
-		}
+	lock, err := mcgen.LoadLockfile(lockPath(*lockFlag, *configPath))
+	if err != nil {
+		log.Fatalf("load lockfile: %v", err)
+	}
+	client := newMetaClient(cfg, *workDir)
+
+	versionsToProcess, err := selectVersions(cfg, *versionsStr, client, lock, *offline)
+	if err != nil {
+		log.Fatalf("discover versions: %v", err)
+	}
+	if err := validateOverrides(cfg, *versionsStr, versionsToProcess); err != nil {
+		log.Fatalf("load config: %v", err)
 	}
 
 	// Override decompile sources if flag is set
@@ -51,25 +96,34 @@ func main() {
 	fmt.Printf("Using config: %s\n", *configPath)
 	fmt.Printf("Work dir:     %s\n", *workDir)
 	fmt.Printf("Output dir:   %s\n", cfg.OutputDir)
+	fmt.Printf("Lockfile:     %s\n", lock.Path())
+	if *offline {
+		fmt.Printf("Offline: using locked Fabric versions only\n")
+	}
 	if *generateSrc {
 		fmt.Printf("Decompiling sources: enabled\n")
 	}
+	fmt.Printf("Versions:     %s\n", strings.Join(versionsToProcess, ", "))
 
-	// Track results for all versions
-	var results []versionResult
+	// Ctrl-C cancels every running Gradle build; versions not yet started
+	// are reported as cancelled.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	for _, v := range versionsToProcess {
-		fmt.Printf("\n=== Generating data for %s ===\n", v)
-
-		if err := processVersion(v, *workDir, cfg); err != nil {
-			fmt.Printf("❌ FAILED: %s - %v\n", v, err)
-			results = append(results, versionResult{version: v, success: false, err: err})
-		} else {
-			fmt.Printf("✅ Done %s\n", v)
-			results = append(results, versionResult{version: v, success: true})
-		}
-
-		runtime.GC()
+	g := &generator{
+		workDir: *workDir,
+		cfg:     cfg,
+		force:   *force,
+		lock:    lock,
+		meta:    client,
+		offline: *offline,
+	}
+	var results []versionResult
+	if *jobs == 1 {
+		results = g.runSequential(ctx, versionsToProcess)
+	} else {
+		fmt.Printf("Jobs:         %d\n", *jobs)
+		results = g.runParallel(ctx, versionsToProcess, *jobs)
 	}
 
 	// Print summary
@@ -78,9 +132,17 @@ func main() {
 	for _, r := range results {
 		if r.success {
 			successCount++
-			fmt.Printf("✅ %s\n", r.version)
+			if r.skipped {
+				fmt.Printf("⏭️  %s (up to date)\n", r.version)
+			} else {
+				fmt.Printf("✅ %s\n", r.version)
+			}
 		} else {
 			fmt.Printf("❌ %s: %v\n", r.version, r.err)
+			if r.logPath != "" {
+				fmt.Printf("   log: %s\n", r.logPath)
+			}
+			printGradleFailure(r.err, *tailLines)
 		}
 	}
 
@@ -91,53 +153,357 @@ func main() {
 	}
 }
 
-// processVersion handles the complete workflow for a single version
-func processVersion(version, workDir string, cfg *mcgen.Config) error {
-	meta, err := mcgen.ResolveFabricMeta(version)
+// printGradleFailure prints the reason, log file and last lines of output
+// of a failed Gradle run; other errors print nothing.
+func printGradleFailure(err error, tailLines int) {
+	var gerr *mcgen.GradleError
+	if !errors.As(err, &gerr) {
+		return
+	}
+	fmt.Printf("   reason: %s\n", gerr.Reason)
+	fmt.Printf("   gradle log: %s\n", gerr.LogPath)
+	tail, tailErr := gerr.Tail(tailLines)
+	if tailErr != nil {
+		fmt.Printf("   (could not read gradle log: %v)\n", tailErr)
+		return
+	}
+	for _, line := range tail {
+		fmt.Printf("   | %s\n", line)
+	}
+}
+
+// updateLock implements `mc-data-gen update-lock`: it re-resolves the
+// Fabric versions for the selected Minecraft versions and rewrites the
+// lockfile, without generating anything.
+func updateLock(args []string) {
+	fs := flag.NewFlagSet("update-lock", flag.ExitOnError)
+	configPath := fs.String("config", "mc-data-gen.yaml", "path to config file (YAML)")
+	lockFlag := fs.String("lock", "", "lockfile to write (default: "+mcgen.DefaultLockfileName+" next to the config)")
+	versionsStr := fs.String("versions", "", "comma-separated list of versions to refresh (if empty, use all from config)")
+	workDir := fs.String("work-dir", "./work", "directory holding the Fabric Meta response cache")
+	fs.Parse(args)
+
+	cfg, err := mcgen.LoadConfig(*configPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+	lock, err := mcgen.LoadLockfile(lockPath(*lockFlag, *configPath))
 	if err != nil {
-		return fmt.Errorf("resolve fabric meta: %w", err)
+		log.Fatalf("load lockfile: %v", err)
 	}
 
-	fmt.Printf("\n%#v\n\n", meta)
+	// Refreshing means asking the server, so the cache only serves as a
+	// fallback here.
+	cfg.FabricMeta.CacheTTL = 0
+	client := newMetaClient(cfg, *workDir)
+
+	versions, err := selectVersions(cfg, *versionsStr, client, lock, false)
+	if err != nil {
+		log.Fatalf("discover versions: %v", err)
+	}
+	fmt.Printf("Versions: %s\n\n", strings.Join(versions, ", "))
 
-	fmt.Printf("  minecraft_version = %s\n", meta.MinecraftVersion)
-	fmt.Printf("  yarn_mappings     = %s\n", meta.YarnVersion)
-	fmt.Printf("  loader_version    = %s\n", meta.LoaderVersion)
-	fmt.Printf("  fabric_api_version= %s\n", meta.FabricAPIVersion)
-	fmt.Printf("  loom_version      = %s\n", meta.LoomVersion)
+	rules := cfg.Rules()
+	failed := 0
+	for _, v := range versions {
+		plan, err := rules.For(v)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", v, err)
+			failed++
+			continue
+		}
+		meta, err := client.Resolve(v, cfg.Selection)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", v, err)
+			failed++
+			continue
+		}
+		meta.LoomVersion = plan.Loom
+		if old, ok := lock.Get(v); ok && old == *meta {
+			fmt.Printf("   %s unchanged\n", v)
+		} else {
+			fmt.Printf("✅ %s: yarn=%s loader=%s fabric-api=%s loom=%s\n",
+				v, meta.YarnVersion, meta.LoaderVersion, meta.FabricAPIVersion, meta.LoomVersion)
+		}
+		lock.Set(v, *meta)
+	}
 
-	projectDir := filepath.Join(workDir, version)
+	if err := lock.Save(); err != nil {
+		log.Fatalf("save lockfile: %v", err)
+	}
+	fmt.Printf("\nWrote %s\n", lock.Path())
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// newMetaClient builds the Fabric Meta client for cfg, keeping its
+// response cache under workDir unless the config names a cache_dir.
+func newMetaClient(cfg *mcgen.Config, workDir string) *mcgen.MetaClient {
+	metaCfg := cfg.FabricMeta
+	if metaCfg.CacheDir == "" {
+		metaCfg.CacheDir = filepath.Join(workDir, "meta-cache")
+	}
+	return mcgen.NewMetaClient(metaCfg)
+}
+
+// selectVersions returns the comma-separated versions from the -versions
+// flag or, when it is empty, the config's versions plus those its
+// discover_versions selectors match in Fabric Meta's game list. Offline,
+// selectors only match versions already in the lockfile.
+func selectVersions(cfg *mcgen.Config, versionsStr string, client *mcgen.MetaClient, lock *mcgen.Lockfile, offline bool) ([]string, error) {
+	if versionsStr != "" {
+		versions := strings.Split(strings.TrimSpace(versionsStr), ",")
+		for i := range versions {
+			versions[i] = strings.TrimSpace(versions[i])
+		}
+		return versions, nil
+	}
+	if len(cfg.DiscoverVersions) == 0 {
+		return cfg.Versions, nil
+	}
+
+	var games []mcgen.GameVersion
+	if offline {
+		for _, v := range lock.Versions() {
+			games = append(games, mcgen.GameVersion{Version: v, Stable: !mcgen.IsPrerelease(v)})
+		}
+	} else {
+		var err error
+		if games, err = client.GameVersions(); err != nil {
+			return nil, err
+		}
+	}
+	versions := mcgen.ExpandVersions(cfg.Versions, cfg.DiscoverVersions, games)
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions listed and none matched discover_versions")
+	}
+	return versions, nil
+}
+
+// validateOverrides checks the config's version_overrides against the
+// versions this run knows about without asking Fabric Meta: the run list,
+// plus the config's static versions when -versions narrowed it. With
+// -versions and discover_versions, discovered versions aren't known, so
+// overrides can't be checked and are accepted.
+func validateOverrides(cfg *mcgen.Config, versionsStr string, run []string) error {
+	if versionsStr == "" {
+		return cfg.ValidateOverrides(run)
+	}
+	if len(cfg.DiscoverVersions) > 0 {
+		return nil
+	}
+	known := append(append([]string(nil), cfg.Versions...), run...)
+	return cfg.ValidateOverrides(known)
+}
+
+// lockPath returns the -lock flag, defaulting to the lockfile next to the
+// config file.
+func lockPath(flagValue, configPath string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return filepath.Join(filepath.Dir(configPath), mcgen.DefaultLockfileName)
+}
+
+// runSequential generates one version at a time, streaming everything to
+// stdout.
+func (g *generator) runSequential(ctx context.Context, versions []string) []versionResult {
+	var results []versionResult
+	for _, v := range versions {
+		if ctx.Err() != nil {
+			results = append(results, versionResult{version: v, err: ctx.Err()})
+			continue
+		}
+		fmt.Printf("\n=== Generating data for %s ===\n", v)
+
+		if skipped, err := g.processVersion(ctx, v, os.Stdout, 0); err != nil {
+			fmt.Printf("❌ FAILED: %s - %v\n", v, err)
+			results = append(results, versionResult{version: v, success: false, err: err})
+		} else {
+			fmt.Printf("✅ Done %s\n", v)
+			results = append(results, versionResult{version: v, success: true, skipped: skipped})
+		}
+
+		runtime.GC()
+	}
+	return results
+}
+
+// runParallel generates up to jobs versions at once. Each version writes
+// its progress and Gradle output to its own log file; stdout only gets one
+// line per start and finish. Results keep the order of versions.
+func (g *generator) runParallel(ctx context.Context, versions []string, jobs int) []versionResult {
+	results := make([]versionResult, len(versions))
+	next := make(chan int)
+	var printMu sync.Mutex
+	printf := func(format string, args ...any) {
+		printMu.Lock()
+		defer printMu.Unlock()
+		fmt.Printf(format, args...)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < len(versions); w++ {
+		wg.Add(1)
+		go func(port int) {
+			defer wg.Done()
+			for i := range next {
+				v := versions[i]
+				if ctx.Err() != nil {
+					results[i] = versionResult{version: v, err: ctx.Err()}
+					continue
+				}
+				r := versionResult{version: v, logPath: filepath.Join(g.workDir, v+".log")}
+				printf("▶ %s (log: %s)\n", v, r.logPath)
+				r.skipped, r.err = g.processVersionLogged(ctx, v, r.logPath, port)
+				r.success = r.err == nil
+				if r.success {
+					printf("✅ Done %s\n", v)
+				} else {
+					printf("❌ FAILED: %s - %v\n", v, r.err)
+				}
+				results[i] = r
+				runtime.GC()
+			}
+		}(basePort + w)
+	}
+	for i := range versions {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// processVersionLogged runs processVersion with its output in logPath.
+func (g *generator) processVersionLogged(ctx context.Context, version, logPath string, port int) (skipped bool, err error) {
+	f, err := os.Create(logPath)
+	if err != nil {
+		return false, fmt.Errorf("create log: %w", err)
+	}
+	defer f.Close()
+	skipped, err = g.processVersion(ctx, version, f, port)
+	if err != nil {
+		fmt.Fprintf(f, "\n❌ FAILED: %v\n", err)
+	}
+	return skipped, err
+}
+
+// processVersion handles the complete workflow for a single version,
+// writing progress to out. A non-zero port is written to the project's
+// server.properties. Versions whose build manifest matches the current
+// inputs and outputs are skipped unless g.force is set.
+func (g *generator) processVersion(ctx context.Context, version string, out io.Writer, port int) (skipped bool, err error) {
+	cfg := g.cfg
+	settings := cfg.ForVersion(version)
+
+	rules := cfg.Rules()
+	plan, err := rules.For(version)
+	if err != nil {
+		return false, fmt.Errorf("build rules: %w", err)
+	}
+	meta, err := g.lock.Resolve(g.meta, version, cfg.Selection, rules, g.offline)
+	if err != nil {
+		return false, fmt.Errorf("resolve fabric meta: %w", err)
+	}
+	// The build rules always decide Loom, so editing a rule applies to
+	// locked versions too and, through the manifest, triggers a rebuild.
+	// A loader pinned by the version's override beats the lockfile.
+	if meta.LoomVersion != plan.Loom || (settings.LoaderVersion != "" && settings.LoaderVersion != meta.LoaderVersion) {
+		pinned := *meta
+		pinned.LoomVersion = plan.Loom
+		if settings.LoaderVersion != "" {
+			pinned.LoaderVersion = settings.LoaderVersion
+		}
+		meta = &pinned
+	}
+
+	fmt.Fprintf(out, "\n%#v\n\n", meta)
+
+	fmt.Fprintf(out, "  minecraft_version = %s\n", meta.MinecraftVersion)
+	fmt.Fprintf(out, "  yarn_mappings     = %s\n", meta.YarnVersion)
+	fmt.Fprintf(out, "  loader_version    = %s\n", meta.LoaderVersion)
+	fmt.Fprintf(out, "  fabric_api_version= %s\n", meta.FabricAPIVersion)
+	fmt.Fprintf(out, "  loom_version      = %s\n", meta.LoomVersion)
+	fmt.Fprintf(out, "  java_version      = %d\n", plan.Java)
+
+	projectDir := filepath.Join(g.workDir, version)
+
+	templateDir := settings.TemplateDir
+	if templateDir == "" {
+		templateDir = mcgen.TemplateDir(plan, cfg.FabricTemplateDir, cfg.FabricTemplateUnobfDir)
+	}
+	if err := mcgen.CheckDir(templateDir); err != nil {
+		return false, fmt.Errorf("template: %w", err)
+	}
+
+	// java_version comes from the build rules; an override may replace it.
+	gradleProps := map[string]string{"java_version": strconv.Itoa(plan.Java)}
+	for k, v := range settings.GradleProperties {
+		gradleProps[k] = v
+	}
+
+	manifest, err := mcgen.NewBuildManifest(version, meta, templateDir, settings.GradleTask, settings.GeneratorOutputRel, cfg.DecompileSources)
+	if err != nil {
+		return false, fmt.Errorf("build manifest: %w", err)
+	}
+	manifest.GradleProperties = gradleProps
+	manifest.JVMHeap = settings.JVMHeap
+	if ok, reason := mcgen.UpToDate(manifest, cfg.OutputDir); ok && !g.force {
+		fmt.Fprintf(out, "  ⏭️  Up to date, skipping (use -force to regenerate)\n")
+		return true, nil
+	} else if !ok {
+		fmt.Fprintf(out, "  Rebuilding: %s\n", reason)
+	}
 
-	templateDir := mcgen.TemplateDir(meta, cfg.FabricTemplateDir, cfg.FabricTemplateUnobfDir)
 	if err := mcgen.PrepareProject(templateDir, projectDir, meta); err != nil {
-		return fmt.Errorf("prepare project: %w", err)
+		return false, fmt.Errorf("prepare project: %w", err)
+	}
+	if err := mcgen.SetGradleProperties(projectDir, gradleProps); err != nil {
+		return false, fmt.Errorf("prepare project: %w", err)
+	}
+	if settings.JVMHeap != "" {
+		if err := mcgen.SetJVMHeap(projectDir, settings.JVMHeap); err != nil {
+			return false, fmt.Errorf("prepare project: %w", err)
+		}
 	}
 
-	if err := mcgen.RunGradle(projectDir, cfg.GradleTask); err != nil {
-		return fmt.Errorf("gradle failed: %w", err)
+	if port != 0 {
+		if err := mcgen.SetServerPort(projectDir, port); err != nil {
+			return false, fmt.Errorf("prepare project: %w", err)
+		}
 	}
 
-	if err := mcgen.CollectOutput(projectDir, cfg.GeneratorOutputRel, cfg.OutputDir, version); err != nil {
-		return fmt.Errorf("collect output: %w", err)
+	if err := mcgen.RunGradle(ctx, projectDir, out, settings.GradleTask); err != nil {
+		return false, err
+	}
+
+	if err := mcgen.CollectOutput(projectDir, settings.GeneratorOutputRel, cfg.OutputDir, version); err != nil {
+		return false, fmt.Errorf("collect output: %w", err)
 	}
 
 	// Decompile sources if enabled
 	if cfg.DecompileSources {
-		fmt.Printf("  Decompiling sources...\n")
-		if err := mcgen.DecompileSources(projectDir); err != nil {
-			return fmt.Errorf("decompile sources: %w", err)
+		fmt.Fprintf(out, "  Decompiling sources...\n")
+		if err := mcgen.DecompileSources(ctx, projectDir, out); err != nil {
+			return false, fmt.Errorf("decompile sources: %w", err)
 		}
-		fmt.Printf("  ✅ Sources extracted to %s/extracted_src\n", projectDir)
+		fmt.Fprintf(out, "  ✅ Sources extracted to %s/extracted_src\n", projectDir)
 
 		// Copy extracted sources to extractedSrc directory
 		srcDir := filepath.Join(projectDir, "extracted_src")
 		dstDir := filepath.Join("extractedSrc", version)
-		fmt.Printf("  Copying sources to %s...\n", dstDir)
+		fmt.Fprintf(out, "  Copying sources to %s...\n", dstDir)
 		if err := mcgen.CopyDir(srcDir, dstDir); err != nil {
-			return fmt.Errorf("copy extracted sources: %w", err)
+			return false, fmt.Errorf("copy extracted sources: %w", err)
 		}
-		fmt.Printf("  ✅ Sources copied to %s\n", dstDir)
+		fmt.Fprintf(out, "  ✅ Sources copied to %s\n", dstDir)
+	}
+
+	if err := mcgen.WriteManifest(manifest, cfg.OutputDir); err != nil {
+		return false, fmt.Errorf("write manifest: %w", err)
 	}
 
-	return nil
+	return false, nil
 }
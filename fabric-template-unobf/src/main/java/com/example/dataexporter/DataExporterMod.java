@@ -3,15 +3,19 @@ package com.example.dataexporter;
 import com.google.gson.Gson;
 import com.google.gson.GsonBuilder;
 import com.google.gson.JsonArray;
+import com.google.gson.JsonElement;
 import com.google.gson.JsonObject;
+import com.google.gson.JsonParser;
 import net.fabricmc.api.ModInitializer;
 import net.fabricmc.fabric.api.event.lifecycle.v1.ServerLifecycleEvents;
 
 import net.minecraft.core.BlockPos;
 import net.minecraft.core.Holder;
 import net.minecraft.core.registries.BuiltInRegistries;
+import net.minecraft.core.registries.Registries;
 import net.minecraft.resources.Identifier;
 import net.minecraft.server.MinecraftServer;
+import net.minecraft.server.packs.resources.Resource;
 import net.minecraft.server.level.ServerLevel;
 import net.minecraft.server.level.ServerPlayer;
 import net.minecraft.tags.BlockTags;
@@ -35,8 +39,11 @@ import net.minecraft.world.item.Item;
 import net.minecraft.world.item.ItemStack;
 import net.minecraft.world.item.Items;
 import net.minecraft.world.item.Rarity;
+import net.minecraft.world.item.component.Tool;
 import net.minecraft.world.level.block.Block;
 import net.minecraft.world.level.block.state.BlockState;
+import net.minecraft.world.level.block.state.properties.BooleanProperty;
+import net.minecraft.world.level.block.state.properties.IntegerProperty;
 import net.minecraft.world.level.block.state.properties.Property;
 import net.minecraft.world.phys.AABB;
 import net.minecraft.world.phys.shapes.CollisionContext;
@@ -83,6 +90,7 @@ public class DataExporterMod implements ModInitializer {
                 dumpItems(server);
                 dumpEntities(server);
                 dumpPoses(server);
+                dumpTags(server);
             } catch (IOException e) {
                 LOGGER.error("[DataExporter] Failed to dump data", e);
             } catch (IllegalAccessException e) {
@@ -111,10 +119,15 @@ public class DataExporterMod implements ModInitializer {
         for (Block block : BuiltInRegistries.BLOCK) {
             Identifier id = BuiltInRegistries.BLOCK.getKey(block);
 
+            JsonArray propertyDefs = serializePropertyDefs(block);
+            JsonObject defaultState = serializeProperties(block.defaultBlockState());
+            JsonArray blockTags = serializeBlockTags(block);
+
             for (BlockState state : block.getStateDefinition().getPossibleStates()) {
                 JsonObject entry = new JsonObject();
                 entry.addProperty("block_id", id.toString());
                 entry.add("properties", serializeProperties(state));
+                entry.addProperty("state_id", Block.getId(state));
 
                 VoxelShape collision = state.getCollisionShape(level, pos, ctx);
                 entry.add("collision_boxes", serializeShape(collision));
@@ -167,14 +180,26 @@ public class DataExporterMod implements ModInitializer {
                         ? blockItem.getDefaultMaxStackSize() : 0;
                 entry.addProperty("stack_size", stackSize);
 
+                if (blockItem != null && blockItem != Items.AIR) {
+                    entry.addProperty("item_id", BuiltInRegistries.ITEM.getKey(blockItem).toString());
+                }
+
                 entry.addProperty("diggable", hardness >= 0);
+                entry.addProperty("requires_tool", state.requiresCorrectToolForDrops());
 
                 JsonArray materialArray = new JsonArray();
                 if (state.is(BlockTags.MINEABLE_WITH_PICKAXE)) materialArray.add("mineable/pickaxe");
                 if (state.is(BlockTags.MINEABLE_WITH_AXE)) materialArray.add("mineable/axe");
                 if (state.is(BlockTags.MINEABLE_WITH_SHOVEL)) materialArray.add("mineable/shovel");
                 if (state.is(BlockTags.MINEABLE_WITH_HOE)) materialArray.add("mineable/hoe");
+                if (state.is(BlockTags.NEEDS_STONE_TOOL)) materialArray.add("needs_stone_tool");
+                if (state.is(BlockTags.NEEDS_IRON_TOOL)) materialArray.add("needs_iron_tool");
+                if (state.is(BlockTags.NEEDS_DIAMOND_TOOL)) materialArray.add("needs_diamond_tool");
                 entry.add("material", materialArray);
+                entry.add("tags", blockTags);
+
+                entry.add("property_defs", propertyDefs);
+                entry.add("default_state", defaultState);
 
                 allEntries.add(entry);
             }
@@ -238,6 +263,66 @@ public class DataExporterMod implements ModInitializer {
         }
     }
 
+    private void dumpTags(MinecraftServer server) throws IOException {
+        Path runDir = server.getServerDirectory();
+        Path outDir = runDir.resolve("data");
+        Files.createDirectories(outDir);
+        Path outFile = outDir.resolve("tags.json");
+
+        Map<String, Object> all = new LinkedHashMap<>();
+        all.put("block", readTagDefinitions(server, Registries.tagsDirPath(Registries.BLOCK)));
+        all.put("item", readTagDefinitions(server, Registries.tagsDirPath(Registries.ITEM)));
+        all.put("entity_type", readTagDefinitions(server, Registries.tagsDirPath(Registries.ENTITY_TYPE)));
+
+        LOGGER.info("[DataExporter] Writing tag definitions to {}", outFile.toAbsolutePath());
+        try (var writer = new OutputStreamWriter(
+                Files.newOutputStream(outFile),
+                StandardCharsets.UTF_8)) {
+            GSON.toJson(all, writer);
+        }
+    }
+
+    private Map<String, List<String>> readTagDefinitions(MinecraftServer server, String dir) throws IOException {
+        Map<String, List<String>> tags = new TreeMap<>();
+        var resources = server.getResourceManager()
+                .listResourceStacks(dir, path -> path.getPath().endsWith(".json"));
+        for (var e : resources.entrySet()) {
+            Identifier file = e.getKey();
+            String path = file.getPath();
+            String name = path.substring(dir.length() + 1, path.length() - ".json".length());
+            List<String> values = new ArrayList<>();
+            for (Resource resource : e.getValue()) {
+                try (var reader = resource.openAsReader()) {
+                    JsonObject obj = JsonParser.parseReader(reader).getAsJsonObject();
+                    if (obj.has("replace") && obj.get("replace").getAsBoolean()) {
+                        values.clear();
+                    }
+                    for (JsonElement v : obj.getAsJsonArray("values")) {
+                        if (v.isJsonPrimitive()) {
+                            values.add(v.getAsString());
+                            continue;
+                        }
+                        JsonObject ref = v.getAsJsonObject();
+                        boolean required = !ref.has("required") || ref.get("required").getAsBoolean();
+                        values.add(ref.get("id").getAsString() + (required ? "" : "?"));
+                    }
+                }
+            }
+            tags.put(file.getNamespace() + ":" + name, values);
+        }
+        return tags;
+    }
+
+    private JsonArray serializeBlockTags(Block block) {
+        List<String> tags = new ArrayList<>();
+        BuiltInRegistries.BLOCK.wrapAsHolder(block).tags()
+                .forEach(tagKey -> tags.add(tagKey.location().toString()));
+        Collections.sort(tags);
+        JsonArray arr = new JsonArray();
+        tags.forEach(arr::add);
+        return arr;
+    }
+
     private void dumpEntities(MinecraftServer server) throws IOException {
         Path runDir = server.getServerDirectory();
         Path outDir = runDir.resolve("data");
@@ -546,10 +631,37 @@ public class DataExporterMod implements ModInitializer {
         var tool = stack.get(DataComponents.TOOL);
         if (tool != null) {
             components.put("is_tool", true);
+            components.put("tool", serializeTool(tool));
         }
         return components;
     }
 
+    private Map<String, Object> serializeTool(Tool tool) {
+        List<Map<String, Object>> rules = new ArrayList<>();
+        for (Tool.Rule rule : tool.rules()) {
+            Map<String, Object> r = new LinkedHashMap<>();
+            var tag = rule.blocks().unwrapKey();
+            if (tag.isPresent()) {
+                r.put("tag", tag.get().location().toString());
+            } else {
+                List<String> blocks = new ArrayList<>();
+                for (Holder<Block> holder : rule.blocks()) {
+                    blocks.add(BuiltInRegistries.BLOCK.getKey(holder.value()).toString());
+                }
+                r.put("blocks", blocks);
+            }
+            rule.speed().ifPresent(speed -> r.put("speed", speed));
+            rule.correctForDrops().ifPresent(correct -> r.put("correct_for_drops", correct));
+            rules.add(r);
+        }
+
+        Map<String, Object> out = new LinkedHashMap<>();
+        out.put("rules", rules);
+        out.put("default_mining_speed", tool.defaultMiningSpeed());
+        out.put("damage_per_block", tool.damagePerBlock());
+        return out;
+    }
+
     private boolean isWeapon(List<String> tags, Map<String, Object> components) {
         return tags.contains("minecraft:swords") ||
                 tags.contains("minecraft:axes") ||
@@ -569,6 +681,35 @@ public class DataExporterMod implements ModInitializer {
         return obj;
     }
 
+    private JsonArray serializePropertyDefs(Block block) {
+        JsonArray arr = new JsonArray();
+        for (Property<?> property : block.getStateDefinition().getProperties()) {
+            JsonObject o = new JsonObject();
+            o.addProperty("name", property.getName());
+            o.addProperty("type", propertyType(property));
+
+            JsonArray values = new JsonArray();
+            for (Comparable<?> value : property.getPossibleValues()) {
+                @SuppressWarnings({ "rawtypes", "unchecked" })
+                String valueName = ((Property) property).getName((Comparable) value);
+                values.add(valueName);
+            }
+            o.add("values", values);
+            arr.add(o);
+        }
+        return arr;
+    }
+
+    private String propertyType(Property<?> property) {
+        if (property instanceof BooleanProperty) {
+            return "bool";
+        }
+        if (property instanceof IntegerProperty) {
+            return "int";
+        }
+        return "enum";
+    }
+
     private JsonArray serializeShape(VoxelShape shape) {
         JsonArray arr = new JsonArray();
         List<AABB> boxes = shape.toAabbs();
@@ -3,7 +3,9 @@ package com.example.dataexporter;
 import com.google.gson.Gson;
 import com.google.gson.GsonBuilder;
 import com.google.gson.JsonArray;
+import com.google.gson.JsonElement;
 import com.google.gson.JsonObject;
+import com.google.gson.JsonParser;
 import net.fabricmc.api.ModInitializer;
 import net.fabricmc.fabric.api.event.lifecycle.v1.ServerLifecycleEvents;
 
@@ -12,6 +14,7 @@ import net.minecraft.block.BlockState;
 import net.minecraft.block.ShapeContext;
 import net.minecraft.component.ComponentType;
 import net.minecraft.component.DataComponentTypes;
+import net.minecraft.component.type.ToolComponent;
 import net.minecraft.entity.Entity;
 import net.minecraft.entity.EntityPose;
 import net.minecraft.entity.EntityType;
@@ -32,11 +35,15 @@ import net.minecraft.entity.mob.PhantomEntity;
 import net.minecraft.item.Item;
 import net.minecraft.item.ItemStack;
 import net.minecraft.registry.Registries;
+import net.minecraft.registry.RegistryKeys;
 import net.minecraft.registry.entry.RegistryEntry;
 import net.minecraft.registry.tag.BlockTags;
 import net.minecraft.registry.tag.FluidTags;
+import net.minecraft.resource.Resource;
 import net.minecraft.server.MinecraftServer;
 import net.minecraft.server.world.ServerWorld;
+import net.minecraft.state.property.BooleanProperty;
+import net.minecraft.state.property.IntProperty;
 import net.minecraft.state.property.Property;
 import net.minecraft.util.Identifier;
 import net.minecraft.util.Rarity;
@@ -85,6 +92,7 @@ public class DataExporterMod implements ModInitializer {
                 dumpItems(server);
                 dumpEntities(server);
                 dumpPoses(server);
+                dumpTags(server);
             } catch (IOException e) {
                 LOGGER.error("[DataExporter] Failed to dump data", e);
             } catch (IllegalAccessException e) {
@@ -115,11 +123,23 @@ public class DataExporterMod implements ModInitializer {
         for (Block block : Registries.BLOCK) {
             Identifier id = Registries.BLOCK.getId(block);
 
+            // Property schema and default state are block-level; they are
+            // repeated on every state (like hardness) and lifted onto the
+            // per-block file by the sharder.
+            JsonArray propertyDefs = serializePropertyDefs(block);
+            JsonObject defaultState = serializeProperties(block.getDefaultState());
+            // Every block tag the block is in, nested tags already resolved.
+            JsonArray blockTags = serializeBlockTags(block);
+
             for (BlockState state : block.getStateManager().getStates()) {
                 JsonObject entry = new JsonObject();
                 entry.addProperty("block_id", id.toString());
                 entry.add("properties", serializeProperties(state));
 
+                // Global palette ID: this is the value chunk sections and block
+                // update packets carry on the wire for this exact state.
+                entry.addProperty("state_id", Block.getRawIdFromState(state));
+
                 VoxelShape collision = state.getCollisionShape(world, pos, ctx);
                 entry.add("collision_boxes", serializeShape(collision));
 
@@ -182,14 +202,31 @@ public class DataExporterMod implements ModInitializer {
                         ? blockItem.getMaxCount() : 0;
                 entry.addProperty("stack_size", stackSize);
 
+                // Item form of the block, when it has one. Not always the same ID
+                // (e.g. minecraft:redstone_wire places from minecraft:redstone).
+                if (blockItem != null && blockItem != net.minecraft.item.Items.AIR) {
+                    entry.addProperty("item_id", Registries.ITEM.getId(blockItem).toString());
+                }
+
                 entry.addProperty("diggable", hardness >= 0);
+                // Without the correct tool the block drops nothing and breaks
+                // at a third of the speed.
+                entry.addProperty("requires_tool", state.isToolRequired());
 
                 JsonArray materialArray = new JsonArray();
                 if (state.isIn(BlockTags.PICKAXE_MINEABLE)) materialArray.add("mineable/pickaxe");
                 if (state.isIn(BlockTags.AXE_MINEABLE)) materialArray.add("mineable/axe");
                 if (state.isIn(BlockTags.SHOVEL_MINEABLE)) materialArray.add("mineable/shovel");
                 if (state.isIn(BlockTags.HOE_MINEABLE)) materialArray.add("mineable/hoe");
+                // Minimum tool tier for drops
+                if (state.isIn(BlockTags.NEEDS_STONE_TOOL)) materialArray.add("needs_stone_tool");
+                if (state.isIn(BlockTags.NEEDS_IRON_TOOL)) materialArray.add("needs_iron_tool");
+                if (state.isIn(BlockTags.NEEDS_DIAMOND_TOOL)) materialArray.add("needs_diamond_tool");
                 entry.add("material", materialArray);
+                entry.add("tags", blockTags);
+
+                entry.add("property_defs", propertyDefs);
+                entry.add("default_state", defaultState);
                 // --- END BLOCK-LEVEL PROPERTIES ---
 
                 allEntries.add(entry);
@@ -261,6 +298,72 @@ public class DataExporterMod implements ModInitializer {
         }
     }
 
+    private void dumpTags(MinecraftServer server) throws IOException {
+        Path runDir = server.getRunDirectory();
+        Path outDir = runDir.resolve("data");
+        Files.createDirectories(outDir);
+        Path outFile = outDir.resolve("tags.json");
+
+        // Tag definitions as the datapacks declare them, "#ns:tag" entries
+        // included, so consumers can see the hierarchy and not just the
+        // flattened membership the per-object tag lists carry.
+        Map<String, Object> all = new LinkedHashMap<>();
+        all.put("block", readTagDefinitions(server, RegistryKeys.getTagPath(RegistryKeys.BLOCK)));
+        all.put("item", readTagDefinitions(server, RegistryKeys.getTagPath(RegistryKeys.ITEM)));
+        all.put("entity_type", readTagDefinitions(server, RegistryKeys.getTagPath(RegistryKeys.ENTITY_TYPE)));
+
+        LOGGER.info("[DataExporter] Writing tag definitions to {}", outFile.toAbsolutePath());
+        try (var writer = new OutputStreamWriter(
+                Files.newOutputStream(outFile),
+                StandardCharsets.UTF_8)) {
+            GSON.toJson(all, writer);
+        }
+    }
+
+    // readTagDefinitions merges every datapack's copy of each tag under dir
+    // (e.g. "tags/block") in load order, honouring "replace". Optional
+    // entries keep the vanilla "?" suffix.
+    private Map<String, List<String>> readTagDefinitions(MinecraftServer server, String dir) throws IOException {
+        Map<String, List<String>> tags = new TreeMap<>();
+        var resources = server.getResourceManager()
+                .findAllResources(dir, path -> path.getPath().endsWith(".json"));
+        for (var e : resources.entrySet()) {
+            Identifier file = e.getKey();
+            String path = file.getPath();
+            String name = path.substring(dir.length() + 1, path.length() - ".json".length());
+            List<String> values = new ArrayList<>();
+            for (Resource resource : e.getValue()) {
+                try (var reader = resource.getReader()) {
+                    JsonObject obj = JsonParser.parseReader(reader).getAsJsonObject();
+                    if (obj.has("replace") && obj.get("replace").getAsBoolean()) {
+                        values.clear();
+                    }
+                    for (JsonElement v : obj.getAsJsonArray("values")) {
+                        if (v.isJsonPrimitive()) {
+                            values.add(v.getAsString());
+                            continue;
+                        }
+                        JsonObject ref = v.getAsJsonObject();
+                        boolean required = !ref.has("required") || ref.get("required").getAsBoolean();
+                        values.add(ref.get("id").getAsString() + (required ? "" : "?"));
+                    }
+                }
+            }
+            tags.put(file.getNamespace() + ":" + name, values);
+        }
+        return tags;
+    }
+
+    private JsonArray serializeBlockTags(Block block) {
+        List<String> tags = new ArrayList<>();
+        Registries.BLOCK.getEntry(block).streamTags()
+                .forEach(tagKey -> tags.add(tagKey.id().toString()));
+        Collections.sort(tags);
+        JsonArray arr = new JsonArray();
+        tags.forEach(arr::add);
+        return arr;
+    }
+
     private void dumpEntities(MinecraftServer server) throws IOException {
         Path runDir = server.getRunDirectory();
         Path outDir = runDir.resolve("data");
@@ -593,10 +696,40 @@ public class DataExporterMod implements ModInitializer {
         var tool = stack.get(DataComponentTypes.TOOL);
         if (tool != null) {
             components.put("is_tool", true);
+            components.put("tool", serializeTool(tool));
         }
         return components;
     }
 
+    // Rules are kept in order: the game uses the first rule that matches a
+    // block and sets the value being asked for (speed or correct_for_drops).
+    private Map<String, Object> serializeTool(ToolComponent tool) {
+        List<Map<String, Object>> rules = new ArrayList<>();
+        for (ToolComponent.Rule rule : tool.rules()) {
+            Map<String, Object> r = new LinkedHashMap<>();
+            // A rule targets either a block tag or an explicit list of blocks
+            var tag = rule.blocks().getTagKey();
+            if (tag.isPresent()) {
+                r.put("tag", tag.get().id().toString());
+            } else {
+                List<String> blocks = new ArrayList<>();
+                for (RegistryEntry<Block> entry : rule.blocks()) {
+                    blocks.add(Registries.BLOCK.getId(entry.value()).toString());
+                }
+                r.put("blocks", blocks);
+            }
+            rule.speed().ifPresent(speed -> r.put("speed", speed));
+            rule.correctForDrops().ifPresent(correct -> r.put("correct_for_drops", correct));
+            rules.add(r);
+        }
+
+        Map<String, Object> out = new LinkedHashMap<>();
+        out.put("rules", rules);
+        out.put("default_mining_speed", tool.defaultMiningSpeed());
+        out.put("damage_per_block", tool.damagePerBlock());
+        return out;
+    }
+
     private boolean isWeapon(List<String> tags, Map<String, Object> components) {
         // simple heuristics:
         return tags.contains("minecraft:swords") ||
@@ -622,6 +755,37 @@ public class DataExporterMod implements ModInitializer {
         return obj;
     }
 
+    private JsonArray serializePropertyDefs(Block block) {
+        JsonArray arr = new JsonArray();
+        for (Property<?> property : block.getStateManager().getProperties()) {
+            JsonObject o = new JsonObject();
+            o.addProperty("name", property.getName());
+            o.addProperty("type", propertyType(property));
+
+            // getValues() is in declaration order, which is also the order
+            // state IDs are assigned in.
+            JsonArray values = new JsonArray();
+            for (Comparable<?> value : property.getValues()) {
+                @SuppressWarnings({ "rawtypes", "unchecked" })
+                String valueName = ((Property) property).name((Comparable) value);
+                values.add(valueName);
+            }
+            o.add("values", values);
+            arr.add(o);
+        }
+        return arr;
+    }
+
+    private String propertyType(Property<?> property) {
+        if (property instanceof BooleanProperty) {
+            return "bool";
+        }
+        if (property instanceof IntProperty) {
+            return "int";
+        }
+        return "enum";
+    }
+
     private JsonArray serializeShape(VoxelShape shape) {
         JsonArray arr = new JsonArray();
         List<Box> boxes = shape.getBoundingBoxes();
@@ -6,3 +6,5 @@ require (
 	github.com/reallyoldfogie/mc-data-gen/loader v0.0.3
 	gopkg.in/yaml.v3 v3.0.1
 )
+
+replace github.com/reallyoldfogie/mc-data-gen/loader => ./loader
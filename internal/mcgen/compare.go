@@ -0,0 +1,167 @@
+package mcgen
+
+import "strings"
+
+// CompareVersions orders two Maven-style version strings such as
+// "0.103.0+1.21.1", "1.21.1+build.10" or "0.16.0-beta.5", returning -1, 0
+// or 1. It follows Maven's ComparableVersion closely enough for Fabric
+// artifacts:
+//
+//   - numeric parts compare as numbers, so 0.99.0 < 0.103.0;
+//   - missing numeric parts count as zero, so 1.0 == 1.0.0;
+//   - pre-release qualifiers sort before the release they lead up to:
+//     alpha < beta < milestone < rc < snapshot < (release) < sp;
+//   - any other qualifier (e.g. "build") sorts after the release, and
+//     qualifiers compare case-insensitively.
+func CompareVersions(a, b string) int {
+	ta, tb := tokenizeVersion(a), tokenizeVersion(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		var x, y versionToken
+		if i < len(ta) {
+			x = ta[i]
+		} else {
+			x = padToken(tb[i])
+		}
+		if i < len(tb) {
+			y = tb[i]
+		} else {
+			y = padToken(ta[i])
+		}
+		if c := x.compare(y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// IsPrerelease reports whether v carries an alpha, beta, milestone, rc,
+// pre or snapshot qualifier.
+func IsPrerelease(v string) bool {
+	for _, t := range tokenizeVersion(v) {
+		if !t.numeric && qualifierRank(t.text) < rankRelease {
+			return true
+		}
+	}
+	return false
+}
+
+type versionToken struct {
+	numeric bool
+	// text is the number without leading zeros, or the lower-cased
+	// qualifier.
+	text string
+}
+
+// padToken is what a version that has run out of parts compares as
+// against other: zero for numbers, the release marker for qualifiers.
+func padToken(other versionToken) versionToken {
+	if other.numeric {
+		return versionToken{numeric: true, text: "0"}
+	}
+	return versionToken{text: ""}
+}
+
+func (t versionToken) compare(o versionToken) int {
+	switch {
+	case t.numeric && o.numeric:
+		if len(t.text) != len(o.text) {
+			return sign(len(t.text) - len(o.text))
+		}
+		return strings.Compare(t.text, o.text)
+	case t.numeric:
+		// 1.0.1 > 1.0-beta, but also 1.0.1 > 1.0-build.
+		return 1
+	case o.numeric:
+		return -1
+	}
+	rt, ro := qualifierRank(t.text), qualifierRank(o.text)
+	if rt != ro {
+		return sign(rt - ro)
+	}
+	if rt == rankOther {
+		return strings.Compare(t.text, o.text)
+	}
+	return 0
+}
+
+const (
+	rankAlpha = iota
+	rankBeta
+	rankMilestone
+	rankRC
+	rankSnapshot
+	rankRelease
+	rankSP
+	rankOther
+)
+
+func qualifierRank(q string) int {
+	switch q {
+	case "alpha", "a":
+		return rankAlpha
+	case "beta", "b":
+		return rankBeta
+	case "milestone", "m":
+		return rankMilestone
+	case "rc", "cr", "pre":
+		return rankRC
+	case "snapshot":
+		return rankSnapshot
+	case "", "ga", "final", "release":
+		return rankRelease
+	case "sp":
+		return rankSP
+	}
+	return rankOther
+}
+
+// tokenizeVersion splits v on '.', '-', '+' and '_' and wherever digits
+// meet letters, so "1.21.1+build.3" is [1 21 1 build 3].
+func tokenizeVersion(v string) []versionToken {
+	var out []versionToken
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		s := v[start:end]
+		start = -1
+		if isDigit(s[0]) {
+			s = strings.TrimLeft(s, "0")
+			if s == "" {
+				s = "0"
+			}
+			out = append(out, versionToken{numeric: true, text: s})
+			return
+		}
+		out = append(out, versionToken{text: strings.ToLower(s)})
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '.' || c == '-' || c == '+' || c == '_':
+			flush(i)
+		case start >= 0 && isDigit(c) != isDigit(v[start]):
+			flush(i)
+			start = i
+		case start < 0:
+			start = i
+		}
+	}
+	flush(len(v))
+	return out
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
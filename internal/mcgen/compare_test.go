@@ -0,0 +1,66 @@
+package mcgen
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"0.99.0+1.21.1", "0.103.0+1.21.1", -1},
+		{"0.103.0+1.21.1", "0.103.0+1.21.1", 0},
+		{"1.21.1+build.10", "1.21.1+build.9", 1},
+		{"0.16.10", "0.16.9", 1},
+		{"1.0", "1.0.0", 0},
+		{"1.0.1", "1.0", 1},
+		{"0.17.0-beta.1", "0.17.0", -1},
+		{"0.17.0-beta.1", "0.16.10", 1},
+		{"1.0-alpha", "1.0-beta", -1},
+		{"1.0-rc1", "1.0-rc2", -1},
+		{"1.0-RC1", "1.0-rc1", 0},
+		{"1.0-SNAPSHOT", "1.0", -1},
+		{"1.0-rc1", "1.0-SNAPSHOT", -1},
+		{"1.0-sp", "1.0", 1},
+		{"1.0-build", "1.0", 1},
+		{"1.0-beta", "1.0.1", -1},
+		{"1.0007", "1.7", 0},
+		{"1.15-SNAPSHOT", "1.13.3", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestCompareVersionsSorts(t *testing.T) {
+	versions := []string{"0.103.0+1.21.1", "0.99.2+1.21.1", "0.104.0-beta.1+1.21.1", "0.100.0+1.21.1"}
+	sort.Slice(versions, func(i, j int) bool { return CompareVersions(versions[i], versions[j]) < 0 })
+	want := []string{"0.99.2+1.21.1", "0.100.0+1.21.1", "0.103.0+1.21.1", "0.104.0-beta.1+1.21.1"}
+	for i := range want {
+		if versions[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, versions)
+		}
+	}
+}
+
+func TestIsPrerelease(t *testing.T) {
+	for v, want := range map[string]bool{
+		"0.16.10":         false,
+		"0.17.0-beta.1":   true,
+		"1.15-SNAPSHOT":   true,
+		"1.21.1+build.3":  false,
+		"26.1-rc-1":       true,
+		"0.104.0-alpha.2": true,
+	} {
+		if got := IsPrerelease(v); got != want {
+			t.Errorf("IsPrerelease(%q) = %v, want %v", v, got, want)
+		}
+	}
+}
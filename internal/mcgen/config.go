@@ -1,8 +1,13 @@
 package mcgen
 
 import (
+    "errors"
     "fmt"
+    "io/fs"
     "os"
+    "path/filepath"
+    "sort"
+    "strings"
 
     "gopkg.in/yaml.v3"
 )
@@ -13,8 +18,22 @@ type Config struct {
     FabricTemplateUnobfDir  string   `yaml:"fabric_template_unobf_dir"`
     GradleTask              string   `yaml:"gradle_task"`
     Versions                []string `yaml:"versions"`
+    // DiscoverVersions adds every Fabric Meta game version matched by one
+    // of these selectors to Versions; see ExpandVersions.
+    DiscoverVersions        []VersionSelector `yaml:"discover_versions"`
     GeneratorOutputRel      string   `yaml:"generator_output_rel"`
     DecompileSources        bool     `yaml:"decompile_sources"`
+    // Selection chooses the yarn, loader and fabric-api version for each
+    // Minecraft version; unset components use latest-stable.
+    Selection               Selection `yaml:"selection"`
+    // FabricMeta configures the endpoints, retries and response cache used
+    // to resolve those versions.
+    FabricMeta              MetaConfig `yaml:"fabric_meta"`
+    // BuildRules choose the Loom version, template and Java version for
+    // each Minecraft version, ahead of DefaultBuildRules.
+    BuildRules              BuildRules `yaml:"build_rules"`
+    // VersionOverrides replaces global settings for individual versions.
+    VersionOverrides        map[string]VersionOverride `yaml:"version_overrides"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -32,7 +51,8 @@ func LoadConfig(path string) (*Config, error) {
     if cfg.FabricTemplateDir == "" {
         return nil, fmt.Errorf("fabric_template_dir is required")
     }
-    if cfg.FabricTemplateUnobfDir == "" {
+    unobfDefault := cfg.FabricTemplateUnobfDir == ""
+    if unobfDefault {
         cfg.FabricTemplateUnobfDir = "./fabric-template-unobf"
     }
     if cfg.GradleTask == "" {
@@ -41,8 +61,129 @@ func LoadConfig(path string) (*Config, error) {
     if cfg.GeneratorOutputRel == "" {
         cfg.GeneratorOutputRel = "run/collision-data/blocks.json"
     }
-    if len(cfg.Versions) == 0 {
-        return nil, fmt.Errorf("versions list is empty")
+    if len(cfg.Versions) == 0 && len(cfg.DiscoverVersions) == 0 {
+        return nil, fmt.Errorf("versions list is empty and no discover_versions selectors are set")
+    }
+    for i, s := range cfg.DiscoverVersions {
+        if err := s.Validate(); err != nil {
+            return nil, fmt.Errorf("discover_versions[%d]: %w", i, err)
+        }
+    }
+    if err := cfg.Selection.Validate(); err != nil {
+        return nil, err
+    }
+    if err := cfg.BuildRules.Validate(); err != nil {
+        return nil, err
+    }
+    for v, o := range cfg.VersionOverrides {
+        if err := o.Validate(); err != nil {
+            return nil, fmt.Errorf("version_overrides[%s]: %w", v, err)
+        }
+    }
+    if err := cfg.resolvePaths(filepath.Dir(path), unobfDefault); err != nil {
+        return nil, err
     }
     return &cfg, nil
 }
+
+// resolvePaths expands environment variables in the config's paths and
+// makes relative ones relative to baseDir, the config file's directory.
+// Template directories must exist, except the unobfuscated template when
+// it was left at its default, which is checked when a version needs it.
+func (c *Config) resolvePaths(baseDir string, unobfDefault bool) error {
+    type pathField struct {
+        name      string
+        value     *string
+        mustExist bool
+    }
+    fields := []pathField{
+        {"output_dir", &c.OutputDir, false},
+        {"fabric_template_dir", &c.FabricTemplateDir, true},
+        {"fabric_template_unobf_dir", &c.FabricTemplateUnobfDir, !unobfDefault},
+    }
+    if c.FabricMeta.CacheDir != "" {
+        fields = append(fields, pathField{"fabric_meta.cache_dir", &c.FabricMeta.CacheDir, false})
+    }
+    versions := make([]string, 0, len(c.VersionOverrides))
+    for v := range c.VersionOverrides {
+        versions = append(versions, v)
+    }
+    sort.Strings(versions)
+    for _, v := range versions {
+        o := c.VersionOverrides[v]
+        if o.TemplateDir == "" {
+            continue
+        }
+        resolved, err := resolvePath(baseDir, o.TemplateDir)
+        if err == nil {
+            err = CheckDir(resolved)
+        }
+        if err != nil {
+            return fmt.Errorf("version_overrides[%s].template_dir: %w", v, err)
+        }
+        o.TemplateDir = resolved
+        c.VersionOverrides[v] = o
+    }
+
+    for i := range c.BuildRules {
+        r := &c.BuildRules[i]
+        if r.Template == "" || r.Template == TemplateObf || r.Template == TemplateUnobf {
+            continue
+        }
+        fields = append(fields, pathField{fmt.Sprintf("build_rules[%d].template", i), &r.Template, true})
+    }
+
+    for _, f := range fields {
+        resolved, err := resolvePath(baseDir, *f.value)
+        if err == nil && f.mustExist {
+            err = CheckDir(resolved)
+        }
+        if err != nil {
+            return fmt.Errorf("%s: %w", f.name, err)
+        }
+        *f.value = resolved
+    }
+    return nil
+}
+
+// Rules returns the config's build rules followed by DefaultBuildRules.
+func (c *Config) Rules() BuildRules {
+    rules := append(BuildRules(nil), c.BuildRules...)
+    return append(rules, DefaultBuildRules...)
+}
+
+// resolvePath expands ${VAR} and $VAR in p and joins it to baseDir unless
+// it is absolute. Unset variables are an error rather than silently empty.
+func resolvePath(baseDir, p string) (string, error) {
+    var missing []string
+    expanded := os.Expand(p, func(name string) string {
+        v, ok := os.LookupEnv(name)
+        if !ok {
+            missing = append(missing, name)
+        }
+        return v
+    })
+    if len(missing) > 0 {
+        return "", fmt.Errorf("%q uses unset environment variable %s", p, strings.Join(missing, ", "))
+    }
+    if filepath.IsAbs(expanded) {
+        return filepath.Clean(expanded), nil
+    }
+    return filepath.Join(baseDir, expanded), nil
+}
+
+// CheckDir returns a descriptive error unless dir is an existing
+// directory.
+func CheckDir(dir string) error {
+    info, err := os.Stat(dir)
+    if errors.Is(err, fs.ErrNotExist) {
+        return fmt.Errorf("directory %s does not exist", dir)
+    }
+    if err != nil {
+        return err
+    }
+    if !info.IsDir() {
+        return fmt.Errorf("%s is not a directory", dir)
+    }
+    return nil
+}
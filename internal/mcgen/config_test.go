@@ -0,0 +1,105 @@
+package mcgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigResolvesPaths(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "conf")
+	writeFile(t, filepath.Join(dir, "fabric-template", "build.gradle"), "")
+	writeFile(t, filepath.Join(root, "shared", "template-1.21.5", "build.gradle"), "")
+	t.Setenv("MCGEN_TEST_ROOT", root)
+
+	path := filepath.Join(dir, "mc-data-gen.yaml")
+	writeFile(t, path, `
+output_dir: ./data
+fabric_template_dir: fabric-template
+fabric_meta:
+  cache_dir: ${MCGEN_TEST_ROOT}/cache
+versions: ["1.21.5"]
+build_rules:
+  - range: ">=26.2"
+    template: unobf
+  - range: "=1.21.5"
+    template: ../shared/template-1.21.5
+version_overrides:
+  "1.21.5":
+    template_dir: $MCGEN_TEST_ROOT/shared/template-1.21.5
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig error: %v", err)
+	}
+	got := map[string]string{
+		"output_dir":                cfg.OutputDir,
+		"fabric_template_dir":       cfg.FabricTemplateDir,
+		"fabric_template_unobf_dir": cfg.FabricTemplateUnobfDir,
+		"cache_dir":                 cfg.FabricMeta.CacheDir,
+		"template_dir":              cfg.VersionOverrides["1.21.5"].TemplateDir,
+		"named template":            cfg.BuildRules[0].Template,
+		"rule template":             cfg.BuildRules[1].Template,
+	}
+	for field, want := range map[string]string{
+		"output_dir":                filepath.Join(dir, "data"),
+		"fabric_template_dir":       filepath.Join(dir, "fabric-template"),
+		"fabric_template_unobf_dir": filepath.Join(dir, "fabric-template-unobf"),
+		"cache_dir":                 filepath.Join(root, "cache"),
+		"template_dir":              filepath.Join(root, "shared", "template-1.21.5"),
+		"named template":            TemplateUnobf,
+		"rule template":             filepath.Join(root, "shared", "template-1.21.5"),
+	} {
+		if got[field] != want {
+			t.Errorf("%s: got %s, want %s", field, got[field], want)
+		}
+	}
+}
+
+func TestLoadConfigReportsBadPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "fabric-template", "build.gradle"), "")
+	writeFile(t, filepath.Join(dir, "not-a-dir"), "")
+	// t.Setenv restores any existing value once the test ends.
+	t.Setenv("MCGEN_TEST_UNSET", "")
+	os.Unsetenv("MCGEN_TEST_UNSET")
+
+	tests := []struct {
+		name    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:    "missing template",
+			config:  "fabric_template_dir: ./nope",
+			wantErr: "fabric_template_dir: directory " + filepath.Join(dir, "nope") + " does not exist",
+		},
+		{
+			name:    "template is a file",
+			config:  "fabric_template_dir: ./not-a-dir",
+			wantErr: "is not a directory",
+		},
+		{
+			name:    "missing explicit unobf template",
+			config:  "fabric_template_dir: ./fabric-template\nfabric_template_unobf_dir: ./unobf",
+			wantErr: "fabric_template_unobf_dir: directory",
+		},
+		{
+			name:    "unset variable",
+			config:  "fabric_template_dir: ${MCGEN_TEST_UNSET}/template",
+			wantErr: "unset environment variable MCGEN_TEST_UNSET",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "mc-data-gen.yaml")
+			writeFile(t, path, "output_dir: ./data\nversions: [\"1.21.1\"]\n"+tt.config+"\n")
+			_, err := LoadConfig(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package mcgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// GameVersion is one entry of Fabric Meta's game version list.
+type GameVersion struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+}
+
+// GameVersions returns every Minecraft version Fabric Meta knows, newest
+// first.
+func (c *MetaClient) GameVersions() ([]GameVersion, error) {
+	body, err := c.get(c.cfg.MetaURL + "/v2/versions/game")
+	if err != nil {
+		return nil, err
+	}
+	var all []GameVersion
+	if err := json.Unmarshal(body, &all); err != nil {
+		return nil, fmt.Errorf("decode game versions: %w", err)
+	}
+	return all, nil
+}
+
+// VersionSelector picks Minecraft versions from Fabric Meta's game list,
+// so new releases are generated without editing the config.
+type VersionSelector struct {
+	// Range is a list of constraints that must all hold, separated by
+	// commas or spaces, e.g. ">=1.21.1" or ">=1.21.1, <26.1". An empty
+	// range matches every version.
+	Range string `yaml:"range"`
+	// StableOnly keeps full releases only.
+	StableOnly bool `yaml:"stable_only"`
+	// IncludeSnapshots also keeps snapshots (25w14a, 26.1-snapshot-1).
+	// Pre-releases and release candidates are kept unless StableOnly is
+	// set.
+	IncludeSnapshots bool `yaml:"include_snapshots"`
+}
+
+// Validate checks that the range parses and the flags don't conflict.
+func (s VersionSelector) Validate() error {
+	if s.StableOnly && s.IncludeSnapshots {
+		return fmt.Errorf("stable_only and include_snapshots are mutually exclusive")
+	}
+	_, err := parseVersionRange(s.Range)
+	return err
+}
+
+// Matches reports whether g is selected. Versions this tool can't parse,
+// such as April Fools' releases, never match.
+func (s VersionSelector) Matches(g GameVersion) bool {
+	v, err := parseMinecraftVersion(g.Version)
+	if err != nil {
+		return false
+	}
+	if s.StableOnly && (!g.Stable || v.kind != kindRelease) {
+		return false
+	}
+	if v.kind == kindSnapshot && !s.IncludeSnapshots {
+		return false
+	}
+	constraints, err := parseVersionRange(s.Range)
+	if err != nil {
+		return false
+	}
+	for _, c := range constraints {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// ExpandVersions returns explicit followed by every game version matched
+// by one of selectors and not already listed, oldest first.
+func ExpandVersions(explicit []string, selectors []VersionSelector, games []GameVersion) []string {
+	out := append([]string(nil), explicit...)
+	seen := make(map[string]bool, len(explicit))
+	for _, v := range explicit {
+		seen[v] = true
+	}
+
+	var found []minecraftVersion
+	byVersion := map[minecraftVersion]string{}
+	for _, g := range games {
+		if seen[g.Version] {
+			continue
+		}
+		for _, s := range selectors {
+			if s.Matches(g) {
+				v, _ := parseMinecraftVersion(g.Version)
+				found = append(found, v)
+				byVersion[v] = g.Version
+				seen[g.Version] = true
+				break
+			}
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].compare(found[j]) < 0 })
+	for _, v := range found {
+		out = append(out, byVersion[v])
+	}
+	return out
+}
+
+// versionConstraint is one comparison in a VersionSelector range.
+type versionConstraint struct {
+	op      string
+	version minecraftVersion
+}
+
+var constraintRE = regexp.MustCompile(`^(>=|<=|==|=|>|<)?\s*([^\s,<>=]+)`)
+
+func parseVersionRange(r string) ([]versionConstraint, error) {
+	var out []versionConstraint
+	rest := strings.TrimSpace(r)
+	for rest != "" {
+		m := constraintRE.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid version range %q", r)
+		}
+		v, err := parseMinecraftVersion(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q: %w", r, err)
+		}
+		op := m[1]
+		if op == "" || op == "==" {
+			op = "="
+		}
+		out = append(out, versionConstraint{op: op, version: v})
+		rest = strings.TrimLeft(rest[len(m[0]):], ", \t")
+	}
+	return out, nil
+}
+
+func (c versionConstraint) matches(v minecraftVersion) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	}
+	return cmp == 0
+}
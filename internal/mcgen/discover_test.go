@@ -0,0 +1,97 @@
+package mcgen
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func loadGameVersions(t *testing.T) []GameVersion {
+	t.Helper()
+	var games []GameVersion
+	if err := json.Unmarshal(readFixture(t, "game-versions.json"), &games); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	return games
+}
+
+func TestExpandVersions(t *testing.T) {
+	games := loadGameVersions(t)
+	tests := []struct {
+		name      string
+		explicit  []string
+		selectors []VersionSelector
+		want      []string
+	}{
+		{
+			name:      "stable releases from 1.21.1",
+			selectors: []VersionSelector{{Range: ">=1.21.1", StableOnly: true}},
+			want:      []string{"1.21.1", "1.21.2", "1.21.3", "1.21.4", "1.21.5", "1.21.6", "1.21.7", "1.21.8", "1.21.9", "1.21.10", "1.21.11"},
+		},
+		{
+			name:      "pre-releases without snapshots",
+			selectors: []VersionSelector{{Range: ">=1.21.9, <26.1"}},
+			want:      []string{"1.21.9", "1.21.10", "1.21.11-pre5", "1.21.11-rc3", "1.21.11"},
+		},
+		{
+			name:      "snapshots included",
+			selectors: []VersionSelector{{Range: "> 1.21.11", IncludeSnapshots: true}},
+			want:      []string{"26.1-snapshot-1", "26.1-snapshot-2"},
+		},
+		{
+			name:      "explicit versions come first and are not repeated",
+			explicit:  []string{"26.1", "1.21.10"},
+			selectors: []VersionSelector{{Range: ">=1.21.9 <=1.21.10", StableOnly: true}, {Range: "=1.21.1"}},
+			want:      []string{"26.1", "1.21.10", "1.21.1", "1.21.9"},
+		},
+		{
+			name:      "april fools releases are not snapshots",
+			selectors: []VersionSelector{{Range: ">1.21.4, <=1.21.5", IncludeSnapshots: true}},
+			want:      []string{"25w10a", "1.21.5-pre1", "1.21.5-rc1", "1.21.5"},
+		},
+		{
+			name:      "unparseable versions are skipped",
+			selectors: []VersionSelector{{Range: "<1.21", IncludeSnapshots: true}},
+			// April Fools' releases (24w14potato) aren't weekly snapshots.
+			want: []string{"1.8.9", "1.20.6"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range tt.selectors {
+				if err := s.Validate(); err != nil {
+					t.Fatalf("Validate(%+v): %v", s, err)
+				}
+			}
+			got := ExpandVersions(tt.explicit, tt.selectors, games)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionSelectorValidate(t *testing.T) {
+	bad := []VersionSelector{
+		{Range: ">=banana"},
+		{Range: "~1.21"},
+		{StableOnly: true, IncludeSnapshots: true},
+	}
+	for _, s := range bad {
+		if err := s.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", s)
+		}
+	}
+}
+
+func TestMetaClientGameVersions(t *testing.T) {
+	srv := newFabricServer(t, "")
+	c, _ := testClient(srv, "", MetaConfig{})
+	games, err := c.GameVersions()
+	if err != nil {
+		t.Fatalf("GameVersions error: %v", err)
+	}
+	if len(games) == 0 || games[0] != (GameVersion{Version: "26.1-snapshot-2"}) {
+		t.Fatalf("unexpected game versions %+v", games)
+	}
+}
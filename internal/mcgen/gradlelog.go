@@ -0,0 +1,159 @@
+package mcgen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// FailureReason classifies why a Gradle run failed, judged from its output.
+type FailureReason string
+
+const (
+	FailureToolchain    FailureReason = "Java toolchain missing"
+	FailureDependencies FailureReason = "dependency resolution"
+	FailureCompile      FailureReason = "compile error"
+	FailureExporter     FailureReason = "exporter exception"
+	FailureServerCrash  FailureReason = "server crash"
+	FailureUnknown      FailureReason = "unknown"
+)
+
+// failurePatterns are checked in order against every output line; the
+// first reason with a matching line wins. More specific causes come first:
+// an exporter exception usually also crashes the server, and a missing
+// toolchain or dependency stops the build before anything compiles.
+// Dependency patterns only match Gradle's own resolution errors, which name
+// a configuration or a group:name:version coordinate, so JVM and Minecraft
+// messages like "Could not find or load main class" don't match them.
+var failurePatterns = []struct {
+	reason   FailureReason
+	patterns []*regexp.Regexp
+}{
+	{FailureToolchain, literals(
+		"No matching toolchains found",
+		"No locally installed toolchains match",
+		"Cannot find a Java installation",
+		"toolchain download repositories have not been configured",
+	)},
+	{FailureDependencies, []*regexp.Regexp{
+		regexp.MustCompile(`Could not resolve all (files|dependencies|artifacts) for configuration`),
+		regexp.MustCompile(`Could not (resolve|find) [\w.-]+:[\w.-]+:\S+`),
+		regexp.MustCompile(`Could not resolve plugin artifact '`),
+		regexp.MustCompile(`Could not (GET|HEAD) '`),
+		regexp.MustCompile(`Could not download \S+ \([\w.-]+:[\w.-]+`),
+	}},
+	{FailureCompile, literals(
+		"Compilation failed",
+		"Execution failed for task ':compileJava'",
+		": error: ",
+	)},
+	{FailureExporter, literals(
+		"[DataExporter] Failed",
+		"at com.example.dataexporter.",
+	)},
+	{FailureServerCrash, literals(
+		"---- Minecraft Crash Report ----",
+		"This crash report has been saved to",
+		"Encountered an unexpected exception",
+		"Exception in server tick loop",
+	)},
+}
+
+// literals compiles each of s as a pattern matching that exact text.
+func literals(s ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(s))
+	for i, p := range s {
+		out[i] = regexp.MustCompile(regexp.QuoteMeta(p))
+	}
+	return out
+}
+
+// GradleError is returned when Gradle exits unsuccessfully. Its output is
+// in LogPath.
+type GradleError struct {
+	Task    string
+	LogPath string
+	Reason  FailureReason
+	Err     error
+}
+
+func (e *GradleError) Error() string {
+	return fmt.Sprintf("gradle %s failed: %s (%v)", e.Task, e.Reason, e.Err)
+}
+
+func (e *GradleError) Unwrap() error { return e.Err }
+
+// Tail returns the last n lines of the run's output.
+func (e *GradleError) Tail(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	f, err := os.Open(e.LogPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var tail []string
+	err = scanLines(f, func(line string) {
+		if len(tail) == n {
+			tail = tail[1:]
+		}
+		tail = append(tail, line)
+	})
+	return tail, err
+}
+
+// GradleLogPath returns where RunGradleWithArgs writes the output of a
+// run with args: <projectDir>/logs/gradle-<args>.log.
+func GradleLogPath(projectDir string, args ...string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '_'
+		}
+		return r
+	}, strings.TrimLeft(strings.Join(args, "-"), ":"))
+	return filepath.Join(projectDir, "logs", "gradle-"+name+".log")
+}
+
+// ClassifyGradleLog reads Gradle output from r and returns the most
+// specific failure reason it shows evidence of.
+func ClassifyGradleLog(r io.Reader) (FailureReason, error) {
+	best := len(failurePatterns)
+	err := scanLines(r, func(line string) {
+		for i := 0; i < best; i++ {
+			for _, p := range failurePatterns[i].patterns {
+				if p.MatchString(line) {
+					best = i
+					return
+				}
+			}
+		}
+	})
+	if best == len(failurePatterns) {
+		return FailureUnknown, err
+	}
+	return failurePatterns[best].reason, err
+}
+
+// scanLines calls fn for every line of r. Overlong lines are split rather
+// than aborting the scan.
+func scanLines(r io.Reader, fn func(line string)) error {
+	br := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, isPrefix, err := br.ReadLine()
+		if len(line) > 0 || (err == nil && !isPrefix) {
+			fn(string(line))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package mcgen
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestClassifyGradleLog(t *testing.T) {
+	tests := []struct {
+		file string
+		want FailureReason
+	}{
+		{"toolchain.log", FailureToolchain},
+		{"dependencies.log", FailureDependencies},
+		{"compile.log", FailureCompile},
+		{"exporter.log", FailureExporter},
+		{"crash.log", FailureServerCrash},
+		{"exporter-could-not-find.log", FailureExporter},
+		{"crash-could-not-find.log", FailureServerCrash},
+		{"unknown.log", FailureUnknown},
+	}
+	for _, tt := range tests {
+		f, err := os.Open(filepath.Join("testdata", "gradle-logs", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ClassifyGradleLog(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestGradleLogPath(t *testing.T) {
+	if got, want := GradleLogPath("work/1.21.1", "runServer"), filepath.Join("work/1.21.1", "logs", "gradle-runServer.log"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got, want := GradleLogPath("p", ":sub:build", "--info"), filepath.Join("p", "logs", "gradle-sub_build---info.log"); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestRunGradleFailureIsLoggedAndClassified(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake gradlew is a shell script")
+	}
+	projectDir := t.TempDir()
+	fixture, err := filepath.Abs(filepath.Join("testdata", "gradle-logs", "exporter.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\ncat '" + fixture + "'\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(projectDir, "gradlew"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	err = RunGradle(context.Background(), projectDir, &out, "runServer")
+	var gerr *GradleError
+	if !errors.As(err, &gerr) {
+		t.Fatalf("expected a *GradleError, got %v", err)
+	}
+	if gerr.Reason != FailureExporter || gerr.Task != "runServer" {
+		t.Fatalf("unexpected error %+v", gerr)
+	}
+
+	logged, err := os.ReadFile(filepath.Join(projectDir, "logs", "gradle-runServer.log"))
+	if err != nil {
+		t.Fatalf("expected a gradle log: %v", err)
+	}
+	if string(logged) != out.String() || !strings.Contains(out.String(), "BUILD FAILED") {
+		t.Fatalf("expected the output to be teed to the log")
+	}
+
+	tail, err := gerr.Tail(3)
+	if err != nil {
+		t.Fatalf("Tail error: %v", err)
+	}
+	if want := []string{"> Process 'command '/usr/lib/jvm/java-21/bin/java'' finished with non-zero exit value 255", "", "BUILD FAILED in 58s"}; strings.Join(tail, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected tail %q", tail)
+	}
+}
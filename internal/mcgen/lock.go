@@ -0,0 +1,149 @@
+package mcgen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLockfileName is the lockfile's name next to the config file.
+const DefaultLockfileName = "mc-data-gen.lock"
+
+const lockfileHeader = "# Resolved Fabric versions per Minecraft version.\n" +
+	"# Written by mc-data-gen; refresh deliberately with `mc-data-gen update-lock`.\n"
+
+// Lockfile pins the yarn, loader, fabric-api and Loom versions resolved for
+// each Minecraft version, so repeated runs build against the same
+// dependencies and can run without Fabric Meta. The Loom version is a
+// record of what the build rules chose; generation always uses the rules'
+// current choice. It is safe for concurrent use.
+type Lockfile struct {
+	path string
+
+	mu       sync.Mutex
+	versions map[string]FabricMeta
+}
+
+type lockfileData struct {
+	Versions map[string]FabricMeta `yaml:"versions"`
+}
+
+// LoadLockfile reads the lockfile at path. A missing file is an empty
+// lockfile that will be created on the first Save.
+func LoadLockfile(path string) (*Lockfile, error) {
+	l := &Lockfile{path: path, versions: map[string]FabricMeta{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read lockfile: %w", err)
+	}
+	var raw lockfileData
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal lockfile %s: %w", path, err)
+	}
+	for v, meta := range raw.Versions {
+		if meta.MinecraftVersion == "" {
+			meta.MinecraftVersion = v
+		}
+		l.versions[v] = meta
+	}
+	return l, nil
+}
+
+// Path returns where the lockfile is read from and saved to.
+func (l *Lockfile) Path() string {
+	return l.path
+}
+
+// Get returns the locked versions for mcVersion.
+func (l *Lockfile) Get(mcVersion string) (FabricMeta, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	meta, ok := l.versions[mcVersion]
+	return meta, ok
+}
+
+// Versions returns the locked Minecraft versions in no particular order.
+func (l *Lockfile) Versions() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make([]string, 0, len(l.versions))
+	for v := range l.versions {
+		out = append(out, v)
+	}
+	return out
+}
+
+// Set records meta for mcVersion. Call Save to persist it.
+func (l *Lockfile) Set(mcVersion string, meta FabricMeta) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.versions[mcVersion] = meta
+}
+
+// Resolve returns the locked versions for mcVersion. Versions not yet in
+// the lockfile are resolved through client using sel, given the Loom
+// version rules choose, recorded and saved; in offline mode they are an
+// error instead and client may be nil.
+func (l *Lockfile) Resolve(client *MetaClient, mcVersion string, sel Selection, rules BuildRules, offline bool) (*FabricMeta, error) {
+	if meta, ok := l.Get(mcVersion); ok {
+		return &meta, nil
+	}
+	if offline {
+		return nil, fmt.Errorf("%s is not in %s; run update-lock with network access first", mcVersion, l.path)
+	}
+	plan, err := rules.For(mcVersion)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := client.Resolve(mcVersion, sel)
+	if err != nil {
+		return nil, err
+	}
+	meta.LoomVersion = plan.Loom
+	l.Set(mcVersion, *meta)
+	if err := l.Save(); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Save writes the lockfile, replacing it atomically.
+func (l *Lockfile) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	data, err := yaml.Marshal(lockfileData{Versions: l.versions})
+	if err != nil {
+		return fmt.Errorf("marshal lockfile: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(l.path), filepath.Base(l.path)+".*")
+	if err != nil {
+		return fmt.Errorf("write lockfile: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(lockfileHeader)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err == nil {
+		err = tmp.Chmod(0o644)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("write lockfile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.path); err != nil {
+		return fmt.Errorf("write lockfile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package mcgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockfileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultLockfileName)
+	lock, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("LoadLockfile on a missing file: %v", err)
+	}
+	want := FabricMeta{
+		MinecraftVersion: "1.21.1",
+		YarnVersion:      "1.21.1+build.3",
+		LoaderVersion:    "0.16.5",
+		FabricAPIVersion: "0.115.0+1.21.1",
+		LoomVersion:      "1.11-SNAPSHOT",
+	}
+	lock.Set("1.21.1", want)
+	lock.Set("26.1", FabricMeta{MinecraftVersion: "26.1", LoaderVersion: "0.18.0", FabricAPIVersion: "0.140.0+26.1", LoomVersion: "1.15-SNAPSHOT"})
+	if err := lock.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "# ") || !strings.Contains(string(data), "fabric_api_version: 0.115.0+1.21.1") {
+		t.Fatalf("unexpected lockfile contents:\n%s", data)
+	}
+
+	reloaded, err := LoadLockfile(path)
+	if err != nil {
+		t.Fatalf("LoadLockfile error: %v", err)
+	}
+	if got, ok := reloaded.Get("1.21.1"); !ok || got != want {
+		t.Fatalf("expected %+v, got %+v (ok=%v)", want, got, ok)
+	}
+	if got, _ := reloaded.Get("26.1"); got.YarnVersion != "" {
+		t.Fatalf("expected no yarn for 26.1, got %q", got.YarnVersion)
+	}
+
+	// Locked versions resolve without the network, offline or not.
+	if meta, err := reloaded.Resolve(nil, "1.21.1", Selection{}, DefaultBuildRules, true); err != nil || *meta != want {
+		t.Fatalf("expected locked meta, got %+v, %v", meta, err)
+	}
+	if _, err := reloaded.Resolve(nil, "1.20.1", Selection{}, DefaultBuildRules, true); err == nil || !strings.Contains(err.Error(), "update-lock") {
+		t.Fatalf("expected offline miss to point at update-lock, got %v", err)
+	}
+}
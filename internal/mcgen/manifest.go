@@ -0,0 +1,253 @@
+package mcgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// BuildManifest records what a data/<version> directory was generated
+// from and what it contained afterwards. It is written next to the version
+// directory as <version>.manifest.json.
+type BuildManifest struct {
+	Version    string     `json:"version"`
+	Meta       FabricMeta `json:"fabric_meta"`
+	GradleTask string     `json:"gradle_task"`
+	// GeneratorOutputRel is where the exporter's output was collected from.
+	GeneratorOutputRel string `json:"generator_output_rel"`
+	DecompileSources   bool   `json:"decompile_sources"`
+	// OutputFormat is the value of the OutputFormat constant when the
+	// output was collected.
+	OutputFormat int `json:"output_format"`
+	// GradleProperties are the extra gradle.properties entries (build rules
+	// and the version's override); JVMHeap comes from the override.
+	GradleProperties map[string]string `json:"gradle_properties,omitempty"`
+	JVMHeap          string            `json:"jvm_heap,omitempty"`
+	// TemplateHash covers the template's build files; ExporterHash covers
+	// its src/ tree, i.e. the exporter mod itself.
+	TemplateHash string `json:"template_hash"`
+	ExporterHash string `json:"exporter_hash"`
+	// Outputs maps each file under data/<version> (slash-separated,
+	// relative) to its SHA-256.
+	Outputs     map[string]string `json:"outputs"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// ManifestPath returns where the manifest for version lives.
+func ManifestPath(outputRoot, version string) string {
+	return filepath.Join(outputRoot, version+".manifest.json")
+}
+
+// NewBuildManifest hashes the inputs of a build. Outputs is left empty
+// until the build has run.
+func NewBuildManifest(version string, meta *FabricMeta, templateDir, gradleTask, generatorOutputRel string, decompile bool) (*BuildManifest, error) {
+	templateHash, exporterHash, err := HashTemplate(templateDir)
+	if err != nil {
+		return nil, err
+	}
+	return &BuildManifest{
+		Version:            version,
+		Meta:               *meta,
+		GradleTask:         gradleTask,
+		GeneratorOutputRel: generatorOutputRel,
+		DecompileSources:   decompile,
+		OutputFormat:       OutputFormat,
+		TemplateHash:       templateHash,
+		ExporterHash:       exporterHash,
+	}, nil
+}
+
+// ReadManifest loads a manifest written by WriteManifest.
+func ReadManifest(path string) (*BuildManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var m BuildManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// WriteManifest records the hashes of everything now in
+// outputRoot/<version> and writes the manifest next to it.
+func WriteManifest(m *BuildManifest, outputRoot string) error {
+	outputs, err := HashOutputs(filepath.Join(outputRoot, m.Version))
+	if err != nil {
+		return err
+	}
+	m.Outputs = outputs
+	m.GeneratedAt = time.Now().UTC()
+
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(ManifestPath(outputRoot, m.Version), buf, 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return nil
+}
+
+// UpToDate reports whether a previous build of m.Version can be kept: its
+// manifest has the same inputs and the output files still match it. When
+// not, reason says why.
+func UpToDate(m *BuildManifest, outputRoot string) (ok bool, reason string) {
+	prev, err := ReadManifest(ManifestPath(outputRoot, m.Version))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, "no manifest"
+	}
+	if err != nil {
+		return false, err.Error()
+	}
+
+	switch {
+	case prev.Meta != m.Meta:
+		return false, "fabric versions changed"
+	case prev.GradleTask != m.GradleTask:
+		return false, "gradle task changed"
+	case prev.GeneratorOutputRel != m.GeneratorOutputRel:
+		return false, "generator output path changed"
+	case prev.OutputFormat != m.OutputFormat:
+		return false, "output format changed"
+	case !sameStrings(prev.GradleProperties, m.GradleProperties) || prev.JVMHeap != m.JVMHeap:
+		return false, "gradle properties changed"
+	case prev.TemplateHash != m.TemplateHash:
+		return false, "template changed"
+	case prev.ExporterHash != m.ExporterHash:
+		return false, "exporter source changed"
+	case m.DecompileSources && !prev.DecompileSources:
+		return false, "sources not decompiled yet"
+	}
+
+	outputs, err := HashOutputs(filepath.Join(outputRoot, m.Version))
+	if err != nil {
+		return false, err.Error()
+	}
+	if !sameStrings(outputs, prev.Outputs) {
+		return false, "output files changed"
+	}
+	return true, ""
+}
+
+func sameStrings(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+// HashTemplate returns one hash over the template's build files and one
+// over its src/ tree. Gradle caches, build output and the run directory
+// are ignored.
+func HashTemplate(templateDir string) (template, exporter string, err error) {
+	template, err = hashTree(templateDir, func(rel string) bool {
+		switch rel {
+		case ".gradle", "build", "run", "src":
+			return true
+		}
+		return false
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("hash template: %w", err)
+	}
+	exporter, err = hashTree(filepath.Join(templateDir, "src"), nil)
+	if err != nil {
+		return "", "", fmt.Errorf("hash exporter source: %w", err)
+	}
+	return template, exporter, nil
+}
+
+// HashOutputs returns the SHA-256 of every file under dir, keyed by
+// slash-separated relative path.
+func HashOutputs(dir string) (map[string]string, error) {
+	out := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		out[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hash outputs: %w", err)
+	}
+	return out, nil
+}
+
+// hashTree hashes the sorted relative paths and contents of every file
+// under dir. skip is called with top-level entry names.
+func hashTree(dir string, skip func(rel string) bool) (string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && skip != nil && filepath.Dir(rel) == "." && skip(rel) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		files[rel] = sum
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s %s\n", files[name], name)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,97 @@
+package mcgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildManifestUpToDate(t *testing.T) {
+	root := t.TempDir()
+	template := filepath.Join(root, "template")
+	output := filepath.Join(root, "data")
+	writeFile(t, filepath.Join(template, "build.gradle"), "plugins {}")
+	writeFile(t, filepath.Join(template, "src", "main", "java", "Exporter.java"), "class Exporter {}")
+	writeFile(t, filepath.Join(template, "run", "data", "blocks.json"), "[]")
+	writeFile(t, filepath.Join(output, "1.21.1", "poses.json"), "{}")
+
+	meta := &FabricMeta{MinecraftVersion: "1.21.1", LoaderVersion: "0.16.0"}
+	outputRel := "run/data"
+	newManifest := func() *BuildManifest {
+		m, err := NewBuildManifest("1.21.1", meta, template, "runServer", outputRel, false)
+		if err != nil {
+			t.Fatalf("NewBuildManifest error: %v", err)
+		}
+		return m
+	}
+
+	if ok, reason := UpToDate(newManifest(), output); ok || reason != "no manifest" {
+		t.Fatalf("expected no manifest, got ok=%v reason=%q", ok, reason)
+	}
+	if err := WriteManifest(newManifest(), output); err != nil {
+		t.Fatalf("WriteManifest error: %v", err)
+	}
+	if ok, reason := UpToDate(newManifest(), output); !ok {
+		t.Fatalf("expected up to date, got %q", reason)
+	}
+
+	// Files under run/ are build output, not template input.
+	writeFile(t, filepath.Join(template, "run", "data", "blocks.json"), "[1]")
+	if ok, reason := UpToDate(newManifest(), output); !ok {
+		t.Fatalf("expected run/ to be ignored, got %q", reason)
+	}
+
+	writeFile(t, filepath.Join(template, "src", "main", "java", "Exporter.java"), "class Exporter { int x; }")
+	if ok, reason := UpToDate(newManifest(), output); ok || reason != "exporter source changed" {
+		t.Fatalf("expected exporter change, got ok=%v reason=%q", ok, reason)
+	}
+	if err := WriteManifest(newManifest(), output); err != nil {
+		t.Fatalf("WriteManifest error: %v", err)
+	}
+
+	meta.LoaderVersion = "0.16.1"
+	if ok, reason := UpToDate(newManifest(), output); ok || reason != "fabric versions changed" {
+		t.Fatalf("expected meta change, got ok=%v reason=%q", ok, reason)
+	}
+	meta.LoaderVersion = "0.16.0"
+
+	outputRel = "run/export"
+	if ok, reason := UpToDate(newManifest(), output); ok || reason != "generator output path changed" {
+		t.Fatalf("expected generator output change, got ok=%v reason=%q", ok, reason)
+	}
+	outputRel = "run/data"
+
+	// A build collected by older sharding code lacks newer fields.
+	m := newManifest()
+	m.OutputFormat--
+	if err := WriteManifest(m, output); err != nil {
+		t.Fatalf("WriteManifest error: %v", err)
+	}
+	if ok, reason := UpToDate(newManifest(), output); ok || reason != "output format changed" {
+		t.Fatalf("expected output format change, got ok=%v reason=%q", ok, reason)
+	}
+	if err := WriteManifest(newManifest(), output); err != nil {
+		t.Fatalf("WriteManifest error: %v", err)
+	}
+
+	m = newManifest()
+	m.JVMHeap = "6G"
+	if ok, reason := UpToDate(m, output); ok || reason != "gradle properties changed" {
+		t.Fatalf("expected override change, got ok=%v reason=%q", ok, reason)
+	}
+
+	writeFile(t, filepath.Join(output, "1.21.1", "poses.json"), `{"0":"standing"}`)
+	if ok, reason := UpToDate(newManifest(), output); ok || reason != "output files changed" {
+		t.Fatalf("expected output change, got ok=%v reason=%q", ok, reason)
+	}
+}
@@ -0,0 +1,178 @@
+package mcgen
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// mcVersionKind is the kind of Minecraft build a version id names. The
+// zero value is a full release.
+type mcVersionKind int
+
+const (
+	kindRelease mcVersionKind = iota
+	kindReleaseCandidate
+	kindPreRelease
+	kindSnapshot
+)
+
+// rank orders kinds within one target release: snapshots come first and
+// the release last.
+func (k mcVersionKind) rank() int {
+	return int(kindSnapshot - k)
+}
+
+// minecraftVersion is a parsed Minecraft version id. major, minor and
+// patch are the release it is or leads up to, so 1.21.5-pre1 and 25w10a
+// both have 1.21.5.
+type minecraftVersion struct {
+	major int
+	minor int
+	patch int
+	kind  mcVersionKind
+	// build numbers pre-releases, release candidates and 26.1-style
+	// snapshots ("26.1-snapshot-3" is 3).
+	build int
+	// week (year*100+week) and suffix identify weekly snapshots such as
+	// 25w14a; suffix is a single letter.
+	week   int
+	suffix string
+}
+
+var (
+	weeklySnapshotRE = regexp.MustCompile(`^(\d{2})w(\d{2})([a-z])$`)
+	releaseRE        = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?(?:-([a-z]+)-?(\d+))?$`)
+)
+
+// weeklySnapshotTargets maps the first week of each snapshot cycle to the
+// release it led up to, in order. Weekly ids were retired after 1.21.11;
+// 26.1 onwards uses ids like 26.1-snapshot-1.
+var weeklySnapshotTargets = []struct {
+	week    int
+	release string
+}{
+	{2303, "1.19.4"},
+	{2312, "1.20"},
+	{2331, "1.20.2"},
+	{2340, "1.20.3"},
+	{2351, "1.20.5"},
+	{2418, "1.21"},
+	{2433, "1.21.2"},
+	{2444, "1.21.4"},
+	{2502, "1.21.5"},
+	{2515, "1.21.6"},
+	{2531, "1.21.9"},
+	{2541, "1.21.11"},
+}
+
+// parseMinecraftVersion parses a release ("1.21.1", "26.1"), a
+// pre-release or release candidate ("1.21.5-pre1", "1.21.5-rc2",
+// "26.1-pre-1"), a 26.1-style snapshot ("26.1-snapshot-1") or a weekly
+// snapshot ("25w14a"). April Fools' releases such as 24w14potato are
+// rejected.
+func parseMinecraftVersion(version string) (minecraftVersion, error) {
+	if m := weeklySnapshotRE.FindStringSubmatch(version); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		wk, _ := strconv.Atoi(m[2])
+		return parseWeeklySnapshot(version, year*100+wk, m[3])
+	}
+
+	m := releaseRE.FindStringSubmatch(version)
+	if m == nil {
+		return minecraftVersion{}, fmt.Errorf("invalid version format: %s", version)
+	}
+	var v minecraftVersion
+	var err error
+	if v.major, err = strconv.Atoi(m[1]); err != nil {
+		return minecraftVersion{}, fmt.Errorf("invalid major version: %s", m[1])
+	}
+	if v.minor, err = strconv.Atoi(m[2]); err != nil {
+		return minecraftVersion{}, fmt.Errorf("invalid minor version: %s", m[2])
+	}
+	if m[3] != "" {
+		if v.patch, err = strconv.Atoi(m[3]); err != nil {
+			return minecraftVersion{}, fmt.Errorf("invalid patch version: %s", m[3])
+		}
+	}
+	if m[4] == "" {
+		return v, nil
+	}
+
+	switch m[4] {
+	case "snapshot":
+		v.kind = kindSnapshot
+	case "pre":
+		v.kind = kindPreRelease
+	case "rc":
+		v.kind = kindReleaseCandidate
+	default:
+		return minecraftVersion{}, fmt.Errorf("invalid version qualifier %q in %s", m[4], version)
+	}
+	if v.build, err = strconv.Atoi(m[5]); err != nil {
+		return minecraftVersion{}, fmt.Errorf("invalid build number: %s", m[5])
+	}
+	return v, nil
+}
+
+func parseWeeklySnapshot(version string, week int, suffix string) (minecraftVersion, error) {
+	target := ""
+	for _, t := range weeklySnapshotTargets {
+		if week < t.week {
+			break
+		}
+		target = t.release
+	}
+	if target == "" {
+		return minecraftVersion{}, fmt.Errorf("snapshot %s predates %s; its release is unknown", version, weeklySnapshotTargets[0].release)
+	}
+	v, err := parseMinecraftVersion(target)
+	if err != nil {
+		return minecraftVersion{}, err
+	}
+	v.kind = kindSnapshot
+	v.week = week
+	v.suffix = suffix
+	return v, nil
+}
+
+// compare orders v against o: by target release, then snapshots before
+// pre-releases before release candidates before the release, then by
+// build or snapshot week.
+func (v minecraftVersion) compare(o minecraftVersion) int {
+	if c := v.compareRelease(o.major, o.minor, o.patch); c != 0 {
+		return c
+	}
+	if v.kind != o.kind {
+		return sign(v.kind.rank() - o.kind.rank())
+	}
+	if v.build != o.build {
+		return sign(v.build - o.build)
+	}
+	if v.week != o.week {
+		return sign(v.week - o.week)
+	}
+	return strings.Compare(v.suffix, o.suffix)
+}
+
+// compareRelease compares only the release v is or leads up to against
+// major.minor.patch.
+func (v minecraftVersion) compareRelease(major, minor, patch int) int {
+	switch {
+	case v.major != major:
+		return sign(v.major - major)
+	case v.minor != minor:
+		return sign(v.minor - minor)
+	}
+	return sign(v.patch - patch)
+}
+
+// release returns the release v is or leads up to, e.g. "1.21.5" for
+// 25w10a and "26.1" for 26.1-snapshot-1.
+func (v minecraftVersion) release() string {
+	if v.patch == 0 {
+		return fmt.Sprintf("%d.%d", v.major, v.minor)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
@@ -0,0 +1,184 @@
+package mcgen
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Default Fabric endpoints.
+const (
+	DefaultMetaURL  = "https://meta.fabricmc.net"
+	DefaultMavenURL = "https://maven.fabricmc.net"
+)
+
+// MetaConfig is the fabric_meta section of the config: where to query
+// Fabric Meta and the Fabric Maven, and how hard to try.
+type MetaConfig struct {
+	// MetaURL and MavenURL replace the public endpoints, e.g. with a
+	// corporate mirror. Paths below them must match the originals.
+	MetaURL  string `yaml:"meta_url"`
+	MavenURL string `yaml:"maven_url"`
+	// Retries is how many times a failed request is retried (negative
+	// for none); Backoff is the delay before the first retry, doubling for
+	// each one after.
+	Retries int           `yaml:"retries"`
+	Backoff time.Duration `yaml:"backoff"`
+	// Timeout bounds each request.
+	Timeout time.Duration `yaml:"timeout"`
+	// CacheDir keeps the last good response for every URL. Responses
+	// younger than CacheTTL are served without a request; older ones are
+	// used only when every attempt fails. Empty disables the cache.
+	CacheDir string        `yaml:"cache_dir"`
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// MetaClient resolves Fabric component versions over HTTP with retries
+// and an optional disk cache.
+type MetaClient struct {
+	cfg  MetaConfig
+	http *http.Client
+	// sleep waits between retries; tests replace it.
+	sleep func(time.Duration)
+}
+
+// NewMetaClient returns a client for cfg, filling in defaults: the public
+// endpoints, 3 retries starting at 500ms and a 20s timeout.
+func NewMetaClient(cfg MetaConfig) *MetaClient {
+	if cfg.MetaURL == "" {
+		cfg.MetaURL = DefaultMetaURL
+	}
+	if cfg.MavenURL == "" {
+		cfg.MavenURL = DefaultMavenURL
+	}
+	cfg.MetaURL = strings.TrimSuffix(cfg.MetaURL, "/")
+	cfg.MavenURL = strings.TrimSuffix(cfg.MavenURL, "/")
+	switch {
+	case cfg.Retries == 0:
+		cfg.Retries = 3
+	case cfg.Retries < 0:
+		cfg.Retries = 0
+	}
+	if cfg.Backoff == 0 {
+		cfg.Backoff = 500 * time.Millisecond
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 20 * time.Second
+	}
+	return &MetaClient{
+		cfg:   cfg,
+		http:  &http.Client{Timeout: cfg.Timeout},
+		sleep: time.Sleep,
+	}
+}
+
+// errPermanent marks responses that retrying cannot fix.
+type errPermanent struct{ err error }
+
+func (e errPermanent) Error() string { return e.err.Error() }
+func (e errPermanent) Unwrap() error { return e.err }
+
+// get fetches url, serving a fresh cached copy when there is one, retrying
+// network errors, 429s and 5xx responses with exponential backoff, and
+// falling back to a stale cached copy when the server can't be reached.
+func (c *MetaClient) get(url string) ([]byte, error) {
+	cached, age, cacheErr := c.readCache(url)
+	if cacheErr == nil && c.cfg.CacheTTL > 0 && age < c.cfg.CacheTTL {
+		return cached, nil
+	}
+
+	var err error
+	var perm errPermanent
+	delay := c.cfg.Backoff
+	for attempt := 0; attempt <= c.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			c.sleep(delay)
+			delay *= 2
+		}
+		var body []byte
+		body, err = c.fetch(url)
+		if err == nil {
+			c.writeCache(url, body)
+			return body, nil
+		}
+		if errors.As(err, &perm) {
+			// The server answered; a cached copy would hide a real 404.
+			return nil, err
+		}
+	}
+	if cacheErr == nil {
+		return cached, nil
+	}
+	return nil, err
+}
+
+func (c *MetaClient) fetch(url string) ([]byte, error) {
+	resp, err := c.http.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		err := fmt.Errorf("GET %s: status %s, body=%s", url, resp.Status, string(b))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, err
+		}
+		return nil, errPermanent{err}
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", url, err)
+	}
+	return data, nil
+}
+
+func (c *MetaClient) cachePath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.cfg.CacheDir, hex.EncodeToString(sum[:]))
+}
+
+func (c *MetaClient) readCache(url string) (body []byte, age time.Duration, err error) {
+	if c.cfg.CacheDir == "" {
+		return nil, 0, errors.New("cache disabled")
+	}
+	p := c.cachePath(url)
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	body, err = os.ReadFile(p)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, time.Since(info.ModTime()), nil
+}
+
+// writeCache stores body for url. The cache is best-effort: failures only
+// cost a future request.
+func (c *MetaClient) writeCache(url string, body []byte) {
+	if c.cfg.CacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.cfg.CacheDir, 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.cfg.CacheDir, "tmp-*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || os.Rename(tmp.Name(), c.cachePath(url)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
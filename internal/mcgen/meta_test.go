@@ -0,0 +1,186 @@
+package mcgen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fabricServer replays the testdata fixtures under prefix, the way a
+// mirror of meta.fabricmc.net and maven.fabricmc.net would serve them.
+type fabricServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	hits map[string]int
+	// fail answers this many requests with 503 before serving normally.
+	fail int
+}
+
+func newFabricServer(t *testing.T, prefix string) *fabricServer {
+	t.Helper()
+	routes := map[string]string{
+		prefix + "/v2/versions/game":                                      "game-versions.json",
+		prefix + "/v2/versions/yarn/1.21.1":                               "yarn-1.21.1.json",
+		prefix + "/v2/versions/loader/1.21.1":                             "loader-1.21.1.json",
+		prefix + "/v2/versions/loader/26.1":                               "loader-26.1.json",
+		prefix + "/net/fabricmc/fabric-api/fabric-api/maven-metadata.xml": "fabric-api-maven-metadata.xml",
+	}
+	s := &fabricServer{hits: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		fail := s.fail > 0
+		if fail {
+			s.fail--
+		}
+		s.mu.Unlock()
+
+		if fail {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		name, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", name))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fabricServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
+
+func (s *fabricServer) total() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, c := range s.hits {
+		n += c
+	}
+	return n
+}
+
+func testClient(srv *fabricServer, prefix string, cfg MetaConfig) (*MetaClient, *[]time.Duration) {
+	cfg.MetaURL = srv.URL + prefix + "/"
+	cfg.MavenURL = srv.URL + prefix
+	c := NewMetaClient(cfg)
+	var slept []time.Duration
+	c.sleep = func(d time.Duration) { slept = append(slept, d) }
+	return c, &slept
+}
+
+func TestMetaClientResolve(t *testing.T) {
+	srv := newFabricServer(t, "/mirror")
+	c, _ := testClient(srv, "/mirror", MetaConfig{})
+
+	meta, err := c.Resolve("1.21.1", Selection{})
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	want := FabricMeta{
+		MinecraftVersion: "1.21.1",
+		YarnVersion:      "1.21.1+build.9",
+		LoaderVersion:    "0.16.10",
+		FabricAPIVersion: "0.103.0+1.21.1",
+	}
+	if *meta != want {
+		t.Fatalf("expected %+v, got %+v", want, *meta)
+	}
+
+	meta, err = c.Resolve("26.1", Selection{Loader: SelectionPolicy{Policy: PolicyPinned, Version: "0.18.0"}})
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if meta.YarnVersion != "" || meta.LoaderVersion != "0.18.0" || meta.FabricAPIVersion != "0.141.0+26.1" {
+		t.Fatalf("unexpected 26.1 meta %+v", *meta)
+	}
+	if n := srv.count("/mirror/v2/versions/yarn/26.1"); n != 0 {
+		t.Fatalf("expected no yarn lookup for 26.1, got %d", n)
+	}
+
+	if _, err := c.Resolve("1.19.2", Selection{}); err == nil {
+		t.Fatalf("expected an error for a version the server doesn't know")
+	}
+}
+
+func TestMetaClientRetriesWithBackoff(t *testing.T) {
+	srv := newFabricServer(t, "")
+	srv.fail = 2
+	c, slept := testClient(srv, "", MetaConfig{Backoff: 100 * time.Millisecond})
+
+	if _, err := c.Resolve("26.1", Selection{}); err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}
+	if len(*slept) != len(want) || (*slept)[0] != want[0] || (*slept)[1] != want[1] {
+		t.Fatalf("expected backoff %v, got %v", want, *slept)
+	}
+
+	srv.fail = 10
+	c, slept = testClient(srv, "", MetaConfig{Retries: 2})
+	if _, err := c.Resolve("26.1", Selection{}); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Fatalf("expected a 503 after retries ran out, got %v", err)
+	}
+	if len(*slept) != 2 {
+		t.Fatalf("expected 2 retries, got %v", *slept)
+	}
+}
+
+func TestMetaClientDoesNotRetryNotFound(t *testing.T) {
+	srv := newFabricServer(t, "")
+	c, slept := testClient(srv, "", MetaConfig{})
+	if _, err := c.Resolve("1.19.2", Selection{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+	if len(*slept) != 0 || srv.count("/v2/versions/yarn/1.19.2") != 1 {
+		t.Fatalf("expected a single request for a 404, slept %v", *slept)
+	}
+}
+
+func TestMetaClientCache(t *testing.T) {
+	srv := newFabricServer(t, "")
+	cacheDir := t.TempDir()
+
+	c, _ := testClient(srv, "", MetaConfig{CacheDir: cacheDir, CacheTTL: time.Hour})
+	first, err := c.Resolve("1.21.1", Selection{})
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	requests := srv.total()
+	if requests != 3 {
+		t.Fatalf("expected 3 requests, got %d", requests)
+	}
+	if _, err := c.Resolve("1.21.1", Selection{}); err != nil || srv.total() != requests {
+		t.Fatalf("expected fresh responses from the cache, got %d requests, %v", srv.total(), err)
+	}
+
+	// Without a TTL the cache is only a fallback for an unreachable server.
+	c, _ = testClient(srv, "", MetaConfig{CacheDir: cacheDir, Retries: -1})
+	if _, err := c.Resolve("1.21.1", Selection{}); err != nil || srv.total() != 2*requests {
+		t.Fatalf("expected a refetch without a TTL, got %d requests, %v", srv.total(), err)
+	}
+	srv.Close()
+	got, err := c.Resolve("1.21.1", Selection{})
+	if err != nil {
+		t.Fatalf("expected stale cache fallback, got %v", err)
+	}
+	if *got != *first {
+		t.Fatalf("expected %+v from the cache, got %+v", *first, *got)
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("expected 3 cached responses, got %d (%v)", len(entries), err)
+	}
+}
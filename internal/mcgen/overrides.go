@@ -0,0 +1,116 @@
+package mcgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// VersionOverride replaces global settings for one Minecraft version.
+// Empty fields keep the global value.
+type VersionOverride struct {
+	GradleTask string `yaml:"gradle_task"`
+	// TemplateDir is used instead of the template TemplateDir would pick.
+	TemplateDir        string `yaml:"template_dir"`
+	GeneratorOutputRel string `yaml:"generator_output_rel"`
+	// GradleProperties are set in the project's gradle.properties after
+	// the Fabric versions.
+	GradleProperties map[string]string `yaml:"gradle_properties"`
+	// JVMHeap is the maximum heap, e.g. "6G", for both Gradle and the
+	// exporter server.
+	JVMHeap string `yaml:"jvm_heap"`
+	// LoaderVersion pins the Fabric loader, taking precedence over the
+	// lockfile and the selection policy.
+	LoaderVersion string `yaml:"loader_version"`
+}
+
+// VersionSettings are the effective settings for one version: the global
+// config with that version's override merged over it.
+type VersionSettings struct {
+	GradleTask         string
+	GeneratorOutputRel string
+	// TemplateDir is empty unless overridden; use TemplateDir otherwise.
+	TemplateDir      string
+	GradleProperties map[string]string
+	JVMHeap          string
+	LoaderVersion    string
+}
+
+// managedGradleProperties are written from the resolved Fabric versions
+// and can't be overridden through gradle_properties.
+var managedGradleProperties = map[string]string{
+	"minecraft_version":  "",
+	"yarn_mappings":      "selection.yarn",
+	"loader_version":     "loader_version",
+	"fabric_api_version": "selection.fabric_api",
+	"loom_version":       "",
+}
+
+var jvmHeapRE = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+// Validate checks the override's values.
+func (o VersionOverride) Validate() error {
+	keys := make([]string, 0, len(o.GradleProperties))
+	for k := range o.GradleProperties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "" {
+			return fmt.Errorf("gradle_properties: empty key")
+		}
+		if use, ok := managedGradleProperties[k]; ok {
+			if use == "" {
+				return fmt.Errorf("gradle_properties: %s is set by mc-data-gen", k)
+			}
+			return fmt.Errorf("gradle_properties: %s is set by mc-data-gen; use %s instead", k, use)
+		}
+	}
+	if o.JVMHeap != "" && !jvmHeapRE.MatchString(o.JVMHeap) {
+		return fmt.Errorf("jvm_heap: %q is not a size like 4G or 6144M", o.JVMHeap)
+	}
+	return nil
+}
+
+// ForVersion returns the settings for version.
+func (c *Config) ForVersion(version string) VersionSettings {
+	s := VersionSettings{
+		GradleTask:         c.GradleTask,
+		GeneratorOutputRel: c.GeneratorOutputRel,
+	}
+	o, ok := c.VersionOverrides[version]
+	if !ok {
+		return s
+	}
+	if o.GradleTask != "" {
+		s.GradleTask = o.GradleTask
+	}
+	if o.GeneratorOutputRel != "" {
+		s.GeneratorOutputRel = o.GeneratorOutputRel
+	}
+	s.TemplateDir = o.TemplateDir
+	s.GradleProperties = o.GradleProperties
+	s.JVMHeap = o.JVMHeap
+	s.LoaderVersion = o.LoaderVersion
+	return s
+}
+
+// ValidateOverrides rejects overrides for versions not in versions, the
+// list a run will generate, since those are almost always typos.
+func (c *Config) ValidateOverrides(versions []string) error {
+	run := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		run[v] = true
+	}
+	var unknown []string
+	for v := range c.VersionOverrides {
+		if !run[v] {
+			unknown = append(unknown, v)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("version_overrides for versions not in the run list: %v", unknown)
+	}
+	return nil
+}
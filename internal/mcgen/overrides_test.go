@@ -0,0 +1,86 @@
+package mcgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigForVersion(t *testing.T) {
+	cfg := &Config{
+		GradleTask:         "runServer",
+		GeneratorOutputRel: "run/data",
+		VersionOverrides: map[string]VersionOverride{
+			"1.21.5": {
+				GradleTask:       "runDatagen",
+				GradleProperties: map[string]string{"enable_vineflower": "true"},
+				JVMHeap:          "6G",
+				LoaderVersion:    "0.16.10",
+			},
+		},
+	}
+
+	got := cfg.ForVersion("1.21.4")
+	if got.GradleTask != "runServer" || got.GeneratorOutputRel != "run/data" || got.TemplateDir != "" || got.JVMHeap != "" {
+		t.Fatalf("expected the global settings, got %+v", got)
+	}
+	got = cfg.ForVersion("1.21.5")
+	if got.GradleTask != "runDatagen" || got.GeneratorOutputRel != "run/data" || got.JVMHeap != "6G" ||
+		got.LoaderVersion != "0.16.10" || got.GradleProperties["enable_vineflower"] != "true" {
+		t.Fatalf("expected the override merged in, got %+v", got)
+	}
+
+	if err := cfg.ValidateOverrides([]string{"1.21.4", "1.21.5"}); err != nil {
+		t.Fatalf("ValidateOverrides error: %v", err)
+	}
+	if err := cfg.ValidateOverrides([]string{"1.21.4"}); err == nil || !strings.Contains(err.Error(), "1.21.5") {
+		t.Fatalf("expected an error naming 1.21.5, got %v", err)
+	}
+}
+
+func TestVersionOverrideValidate(t *testing.T) {
+	bad := []VersionOverride{
+		{GradleProperties: map[string]string{"loader_version": "0.16.10"}},
+		{GradleProperties: map[string]string{"minecraft_version": "1.21.5"}},
+		{GradleProperties: map[string]string{"": "x"}},
+		{JVMHeap: "-Xmx4G"},
+		{JVMHeap: "lots"},
+	}
+	for _, o := range bad {
+		if err := o.Validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", o)
+		}
+	}
+	ok := VersionOverride{GradleProperties: map[string]string{"org.gradle.caching": "true"}, JVMHeap: "6144m"}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestSetGradlePropertiesAndHeap(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "gradle.properties"),
+		"minecraft_version=1.21.1\norg.gradle.jvmargs=-Xms256m -Xmx4G -XX:+UseG1GC\nenable_vineflower=false\n")
+
+	err := SetGradleProperties(dir, map[string]string{"enable_vineflower": "true", "org.gradle.caching": "true"})
+	if err != nil {
+		t.Fatalf("SetGradleProperties error: %v", err)
+	}
+	if err := SetJVMHeap(dir, "6G"); err != nil {
+		t.Fatalf("SetJVMHeap error: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "gradle.properties"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "minecraft_version=1.21.1\n" +
+		"org.gradle.jvmargs=-Xms256m -Xmx6G -XX:+UseG1GC\n" +
+		"enable_vineflower=true\n" +
+		"org.gradle.caching=true\n" +
+		"server_heap=6G\n"
+	if string(data) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", data, want)
+	}
+}
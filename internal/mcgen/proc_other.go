@@ -0,0 +1,11 @@
+//go:build !unix
+
+package mcgen
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
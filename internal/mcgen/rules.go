@@ -0,0 +1,110 @@
+package mcgen
+
+import (
+	"fmt"
+)
+
+// Template names a BuildRule can use instead of a path.
+const (
+	// TemplateObf is fabric_template_dir, the remapping template.
+	TemplateObf = "obf"
+	// TemplateUnobf is fabric_template_unobf_dir, for unobfuscated 26.1+.
+	TemplateUnobf = "unobf"
+)
+
+// BuildRule says how to build the Minecraft versions in Range. Empty
+// fields are left to later rules.
+type BuildRule struct {
+	// Range is a constraint list as in VersionSelector. Snapshots,
+	// pre-releases and release candidates match as the release they lead
+	// up to, so ">=26.1" covers 26.1-snapshot-1.
+	Range string `yaml:"range"`
+	// Loom is the fabric-loom plugin version.
+	Loom string `yaml:"loom"`
+	// Template is TemplateObf, TemplateUnobf or a template directory.
+	Template string `yaml:"template"`
+	// Java is the toolchain version, written to gradle.properties as
+	// java_version.
+	Java int `yaml:"java"`
+}
+
+// BuildRules are consulted in order; for each field the first matching
+// rule that sets it wins.
+type BuildRules []BuildRule
+
+// DefaultBuildRules cover every version this tool supports out of the box.
+// Rules from the config's build_rules are checked before them.
+var DefaultBuildRules = BuildRules{
+	// 26.1+ is unobfuscated and needs Java 25.
+	{Range: ">=26.1", Loom: "1.15-SNAPSHOT", Template: TemplateUnobf, Java: 25},
+	// Some Fabric API modules for 1.21.11 are built with Loom 1.13.3.
+	{Range: ">=1.21.11", Loom: "1.13.3"},
+	{Loom: "1.11-SNAPSHOT", Template: TemplateObf, Java: 21},
+}
+
+// BuildPlan is what BuildRules choose for one version.
+type BuildPlan struct {
+	Loom     string
+	Template string
+	Java     int
+}
+
+// Validate checks every rule's range and Java version.
+func (r BuildRules) Validate() error {
+	for i, rule := range r {
+		if _, err := parseVersionRange(rule.Range); err != nil {
+			return fmt.Errorf("build_rules[%d]: %w", i, err)
+		}
+		if rule.Java < 0 {
+			return fmt.Errorf("build_rules[%d]: invalid java version %d", i, rule.Java)
+		}
+	}
+	return nil
+}
+
+// For returns the plan for mcVersion. Every field must be set by some
+// matching rule.
+func (r BuildRules) For(mcVersion string) (BuildPlan, error) {
+	v, err := parseMinecraftVersion(mcVersion)
+	if err != nil {
+		return BuildPlan{}, fmt.Errorf("unrecognised minecraft version: %w", err)
+	}
+	release := minecraftVersion{major: v.major, minor: v.minor, patch: v.patch}
+
+	var plan BuildPlan
+	for _, rule := range r {
+		constraints, err := parseVersionRange(rule.Range)
+		if err != nil {
+			return BuildPlan{}, err
+		}
+		matched := true
+		for _, c := range constraints {
+			if !c.matches(release) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		if plan.Loom == "" {
+			plan.Loom = rule.Loom
+		}
+		if plan.Template == "" {
+			plan.Template = rule.Template
+		}
+		if plan.Java == 0 {
+			plan.Java = rule.Java
+		}
+	}
+
+	switch {
+	case plan.Loom == "":
+		return BuildPlan{}, fmt.Errorf("no build rule sets a loom version for %s", mcVersion)
+	case plan.Template == "":
+		return BuildPlan{}, fmt.Errorf("no build rule sets a template for %s", mcVersion)
+	case plan.Java == 0:
+		return BuildPlan{}, fmt.Errorf("no build rule sets a java version for %s", mcVersion)
+	}
+	return plan, nil
+}
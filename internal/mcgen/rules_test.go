@@ -0,0 +1,90 @@
+package mcgen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultBuildRules(t *testing.T) {
+	tests := []struct {
+		version string
+		want    BuildPlan
+	}{
+		{"1.21.1", BuildPlan{Loom: "1.11-SNAPSHOT", Template: TemplateObf, Java: 21}},
+		{"1.21.10", BuildPlan{Loom: "1.11-SNAPSHOT", Template: TemplateObf, Java: 21}},
+		{"25w41a", BuildPlan{Loom: "1.13.3", Template: TemplateObf, Java: 21}},
+		{"1.21.11-pre3", BuildPlan{Loom: "1.13.3", Template: TemplateObf, Java: 21}},
+		{"1.21.11", BuildPlan{Loom: "1.13.3", Template: TemplateObf, Java: 21}},
+		{"26.1-snapshot-1", BuildPlan{Loom: "1.15-SNAPSHOT", Template: TemplateUnobf, Java: 25}},
+		{"26.1", BuildPlan{Loom: "1.15-SNAPSHOT", Template: TemplateUnobf, Java: 25}},
+	}
+	for _, tt := range tests {
+		got, err := DefaultBuildRules.For(tt.version)
+		if err != nil {
+			t.Fatalf("For(%s): %v", tt.version, err)
+		}
+		if got != tt.want {
+			t.Errorf("For(%s) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+	if _, err := DefaultBuildRules.For("not-a-version"); err == nil {
+		t.Errorf("expected an error for an unparseable version")
+	}
+}
+
+func TestBuildRulesFromConfigComeFirst(t *testing.T) {
+	cfg := &Config{BuildRules: BuildRules{
+		{Range: ">=26.2", Loom: "1.16-SNAPSHOT"},
+		{Range: "=1.21.4", Template: "/templates/1.21.4", Java: 17},
+	}}
+	if err := cfg.BuildRules.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+	rules := cfg.Rules()
+
+	// Fields a config rule leaves empty fall through to the defaults.
+	got, err := rules.For("26.2-snapshot-3")
+	if err != nil || got != (BuildPlan{Loom: "1.16-SNAPSHOT", Template: TemplateUnobf, Java: 25}) {
+		t.Fatalf("unexpected 26.2 plan %+v, %v", got, err)
+	}
+	got, err = rules.For("1.21.4")
+	if err != nil || got != (BuildPlan{Loom: "1.11-SNAPSHOT", Template: "/templates/1.21.4", Java: 17}) {
+		t.Fatalf("unexpected 1.21.4 plan %+v, %v", got, err)
+	}
+	if dir := TemplateDir(got, "obf", "unobf"); dir != "/templates/1.21.4" {
+		t.Fatalf("expected the rule's template dir, got %s", dir)
+	}
+
+	if _, err := (BuildRules{{Range: ">=1.21", Loom: "1.11-SNAPSHOT"}}).For("1.21.1"); err == nil {
+		t.Errorf("expected an error when no rule sets a template")
+	}
+	if err := (BuildRules{{Range: "~1.21"}}).Validate(); err == nil {
+		t.Errorf("expected an invalid range to be rejected")
+	}
+}
+
+func TestUpdateLoomVersion(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`    id "fabric-loom" version "1.11-SNAPSHOT"`, `    id "fabric-loom" version "1.13.3"`},
+		{`    id 'net.fabricmc.fabric-loom' version '1.14-SNAPSHOT'`, `    id 'net.fabricmc.fabric-loom' version '1.13.3'`},
+		{`    id "net.fabricmc.fabric-loom" version "${loom_version}"`, `    id "net.fabricmc.fabric-loom" version "${loom_version}"`},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "build.gradle")
+		writeFile(t, path, "plugins {\n"+tt.in+"\n}\n")
+		if err := updateLoomVersion(dir, "1.13.3"); err != nil {
+			t.Fatalf("updateLoomVersion error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "plugins {\n" + tt.want + "\n}\n"; string(data) != want {
+			t.Errorf("got %q, want %q", data, want)
+		}
+	}
+}
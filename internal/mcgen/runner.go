@@ -2,25 +2,32 @@ package mcgen
 
 import (
 	"archive/zip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 
 	loader "github.com/reallyoldfogie/mc-data-gen/loader"
 )
 
-// TemplateDir returns the appropriate template directory for the given Minecraft version.
-// Versions >= 26.1 use the unobfuscated template; older versions use the standard template.
-func TemplateDir(meta *FabricMeta, obfDir, unobfDir string) string {
-	if !needsYarnMappings(meta.MinecraftVersion) {
+// TemplateDir returns the template directory plan names: obfDir or
+// unobfDir for TemplateObf and TemplateUnobf, otherwise plan.Template
+// itself.
+func TemplateDir(plan BuildPlan, obfDir, unobfDir string) string {
+	switch plan.Template {
+	case TemplateObf:
+		return obfDir
+	case TemplateUnobf:
 		return unobfDir
 	}
-	return obfDir
+	return plan.Template
 }
 
 // PrepareProject copies the Fabric template into a per-version dir and
@@ -45,8 +52,6 @@ func PrepareProject(templateDir, projectDir string, meta *FabricMeta) error {
 		return fmt.Errorf("read gradle.properties: %w", err)
 	}
 
-	isUnobf := !needsYarnMappings(meta.MinecraftVersion)
-
 	lines := strings.Split(string(props), "\n")
 	foundMC := false
 	foundYarn := false
@@ -100,9 +105,7 @@ func PrepareProject(templateDir, projectDir string, meta *FabricMeta) error {
 		return fmt.Errorf("write gradle.properties: %w", err)
 	}
 
-	// Only update Loom version in build.gradle for obfuscated builds
-	// The unobf template already has the correct plugin ID and doesn't need rewriting
-	if !isUnobf {
+	if meta.LoomVersion != "" {
 		if err := updateLoomVersion(projectDir, meta.LoomVersion); err != nil {
 			return fmt.Errorf("update loom version: %w", err)
 		}
@@ -111,54 +114,186 @@ func PrepareProject(templateDir, projectDir string, meta *FabricMeta) error {
 	return nil
 }
 
-// updateLoomVersion replaces the Loom version in build.gradle
+// loomPluginRE matches the Loom plugin line of a template's build.gradle
+// under either plugin ID, capturing the version.
+var loomPluginRE = regexp.MustCompile(`(id\s+["'](?:net\.fabricmc\.)?fabric-loom["']\s+version\s+["'])([^"']*)(["'])`)
+
+// updateLoomVersion sets the Loom plugin version in build.gradle. Templates
+// that read it from gradle.properties ("${loom_version}") are left alone.
 func updateLoomVersion(projectDir, loomVersion string) error {
 	buildGradlePath := filepath.Join(projectDir, "build.gradle")
-	
+
 	data, err := os.ReadFile(buildGradlePath)
 	if err != nil {
 		return fmt.Errorf("read build.gradle: %w", err)
 	}
-	
-	// Replace the Loom version line
-	content := string(data)
-	content = strings.ReplaceAll(content,
-		`id "fabric-loom" version "1.11-SNAPSHOT"`,
-		`id "fabric-loom" version "`+loomVersion+`"`)
-	content = strings.ReplaceAll(content,
-		`id "fabric-loom" version "1.14-SNAPSHOT"`,
-		`id "fabric-loom" version "`+loomVersion+`"`)
-	
+
+	content := loomPluginRE.ReplaceAllStringFunc(string(data), func(line string) string {
+		m := loomPluginRE.FindStringSubmatch(line)
+		if strings.Contains(m[2], "${") {
+			return line
+		}
+		return m[1] + loomVersion + m[3]
+	})
+
 	if err := os.WriteFile(buildGradlePath, []byte(content), 0o644); err != nil {
 		return fmt.Errorf("write build.gradle: %w", err)
 	}
-	
 	return nil
 }
 
-// RunGradleWithArgs runs ./gradlew <args...> in the given projectDir.
-func RunGradleWithArgs(projectDir string, args ...string) error {
+// RunGradleWithArgs runs ./gradlew <args...> in the given projectDir,
+// writing its output to out (os.Stdout when nil) and to the log file at
+// GradleLogPath. Cancelling ctx stops Gradle and every JVM it started. A
+// failed run returns a *GradleError classifying the failure.
+func RunGradleWithArgs(ctx context.Context, projectDir string, out io.Writer, args ...string) error {
 	gradlew := "./gradlew"
 	if _, err := os.Stat(filepath.Join(projectDir, "gradlew")); err != nil {
 		return fmt.Errorf("gradlew not found in %s: %w", projectDir, err)
 	}
+	if out == nil {
+		out = os.Stdout
+	}
 
-	cmd := exec.Command(gradlew, append(args, "--no-daemon")...)
+	logPath := GradleLogPath(projectDir, args...)
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		return fmt.Errorf("create gradle log dir: %w", err)
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return fmt.Errorf("create gradle log: %w", err)
+	}
+	defer logFile.Close()
+	out = io.MultiWriter(out, logFile)
+
+	cmd := exec.CommandContext(ctx, gradlew, append(args, "--no-daemon")...)
 	cmd.Dir = projectDir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = out
+	cmd.Stderr = out
+	// gradlew and the Minecraft server run in their own process group so
+	// cancelling takes down the whole tree, not just the wrapper script.
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return terminateProcessGroup(cmd) }
+	cmd.WaitDelay = 30 * time.Second
 
 	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("gradle %v failed: %w", args, err)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("gradle %v: %w", args, ctxErr)
+		}
+		gerr := &GradleError{Task: strings.Join(args, " "), LogPath: logPath, Reason: FailureUnknown, Err: err}
+		if f, openErr := os.Open(logPath); openErr == nil {
+			gerr.Reason, _ = ClassifyGradleLog(f)
+			f.Close()
+		}
+		return gerr
 	}
 	return nil
 }
 
 // RunGradle runs ./gradlew <task> in the given projectDir.
-func RunGradle(projectDir, task string) error {
-	return RunGradleWithArgs(projectDir, task)
+func RunGradle(ctx context.Context, projectDir string, out io.Writer, task string) error {
+	return RunGradleWithArgs(ctx, projectDir, out, task)
+}
+
+// SetServerPort writes run/server.properties so the exporter's server
+// listens on port. Versions generated side by side each need their own.
+func SetServerPort(projectDir string, port int) error {
+	runDir := filepath.Join(projectDir, "run")
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return fmt.Errorf("create run dir: %w", err)
+	}
+	props := fmt.Sprintf("server-port=%d\n", port)
+	if err := os.WriteFile(filepath.Join(runDir, "server.properties"), []byte(props), 0o644); err != nil {
+		return fmt.Errorf("write server.properties: %w", err)
+	}
+	return nil
+}
+
+// SetGradleProperties sets each of props in the project's
+// gradle.properties, replacing existing entries and appending new ones in
+// key order.
+func SetGradleProperties(projectDir string, props map[string]string) error {
+	if len(props) == 0 {
+		return nil
+	}
+	path := filepath.Join(projectDir, "gradle.properties")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read gradle.properties: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	done := make(map[string]bool, len(props))
+	for i, line := range lines {
+		key, _, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if value, set := props[key]; set {
+			lines[i] = key + "=" + value
+			done[key] = true
+		}
+	}
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		if !done[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, k := range keys {
+		lines = append(lines, k+"="+props[k])
+	}
+	if len(keys) > 0 {
+		lines = append(lines, "")
+	}
+
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644); err != nil {
+		return fmt.Errorf("write gradle.properties: %w", err)
+	}
+	return nil
 }
 
+// SetJVMHeap sets the maximum heap of the Gradle JVM (-Xmx in
+// org.gradle.jvmargs) and of the exporter server (server_heap, read by the
+// templates' build.gradle) to heap, e.g. "6G".
+func SetJVMHeap(projectDir, heap string) error {
+	data, err := os.ReadFile(filepath.Join(projectDir, "gradle.properties"))
+	if err != nil {
+		return fmt.Errorf("read gradle.properties: %w", err)
+	}
+	var args []string
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if ok && strings.TrimSpace(key) == "org.gradle.jvmargs" {
+			args = strings.Fields(value)
+		}
+	}
+	replaced := false
+	for i, a := range args {
+		if strings.HasPrefix(a, "-Xmx") {
+			args[i] = "-Xmx" + heap
+			replaced = true
+		}
+	}
+	if !replaced {
+		args = append(args, "-Xmx"+heap)
+	}
+	return SetGradleProperties(projectDir, map[string]string{
+		"org.gradle.jvmargs": strings.Join(args, " "),
+		"server_heap":        heap,
+	})
+}
+
+// OutputFormat versions the layout CollectOutput writes under
+// outputRoot/version. Bump it whenever the sharding code adds, renames or
+// drops a field or file, so builds made by older code are redone.
+const OutputFormat = 1
+
 // CollectOutput copies the generated JSON from the project into outputRoot/version/.
 func CollectOutput(projectDir, generatorOutputRel, outputRoot, version string) error {
 	src := filepath.Join(projectDir, generatorOutputRel)
@@ -182,13 +317,17 @@ func CollectOutput(projectDir, generatorOutputRel, outputRoot, version string) e
 			return fmt.Errorf("collectPoses: %w", err)
 		}
 
+		if err := collectTags(src, outputRoot, version); err != nil {
+			return fmt.Errorf("collectTags: %w", err)
+		}
+
 	return nil
 }
 
 // DecompileSources runs genSources and extracts decompiled Minecraft sources.
-func DecompileSources(projectDir string) error {
+func DecompileSources(ctx context.Context, projectDir string, out io.Writer) error {
 	// Run genSources task to decompile Minecraft
-	if err := RunGradle(projectDir, "genSources"); err != nil {
+	if err := RunGradle(ctx, projectDir, out, "genSources"); err != nil {
 		return fmt.Errorf("genSources failed: %w", err)
 	}
 
@@ -376,6 +515,25 @@ func collectPoses(src, outputRoot, version string) error {
 	return nil
 }
 
+// collectTags copies the tag definitions dump (registry -> tag -> entries,
+// nested "#tag" references kept) into data/<version>/tags.json unchanged.
+func collectTags(src, outputRoot, version string) error {
+	tagsSrc := filepath.Join(src, "tags.json")
+	if _, err := os.Stat(tagsSrc); err != nil {
+		return fmt.Errorf("generator output (tags.json) not found at %s: %w", src, err)
+	}
+
+	versionDir := filepath.Join(outputRoot, version)
+	if err := os.MkdirAll(versionDir, 0o755); err != nil {
+		return fmt.Errorf("create version dir: %w", err)
+	}
+
+	if err := copyFile(tagsSrc, filepath.Join(versionDir, "tags.json")); err != nil {
+		return fmt.Errorf("copy tags.json: %w", err)
+	}
+	return nil
+}
+
 func shardFile(inputPath, outRoot string) error {
 	data, err := os.ReadFile(inputPath)
 	if err != nil {
@@ -392,6 +550,7 @@ func shardFile(inputPath, outRoot string) error {
 	for _, r := range records {
 		slim := loader.BlockStateRecordSlim{
 			Properties:     r.Properties,
+			StateID:        r.StateID,
 			CollisionBoxes: r.CollisionBoxes,
 			OutlineBoxes:   r.OutlineBoxes,
 			Air:            r.Air,
@@ -439,13 +598,18 @@ func shardFile(inputPath, outRoot string) error {
 		props := blockProps[blockID]
 		outFile := filepath.Join(dir, path+".json")
 		file := loader.BlockStatesFile{
-			BlockID:    blockID,
-			Hardness:   props.Hardness,
-			Resistance: props.Resistance,
-			StackSize:  props.StackSize,
-			Diggable:   props.Diggable,
-			Material:   props.Material,
-			States:     states,
+			BlockID:      blockID,
+			Hardness:     props.Hardness,
+			Resistance:   props.Resistance,
+			StackSize:    props.StackSize,
+			Diggable:     props.Diggable,
+			RequiresTool: props.RequiresTool,
+			Material:     props.Material,
+			Tags:         props.Tags,
+			ItemID:       props.ItemID,
+			PropertyDefs: props.PropertyDefs,
+			DefaultState: props.DefaultState,
+			States:       states,
 		}
 		buf, err := json.MarshalIndent(file, "", "  ")
 		if err != nil {
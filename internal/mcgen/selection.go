@@ -0,0 +1,117 @@
+package mcgen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Selection policies for a Fabric component.
+const (
+	// PolicyLatest picks the highest version, stable or not.
+	PolicyLatest = "latest"
+	// PolicyLatestStable picks the highest stable version, falling back to
+	// the highest version when none is stable. This is the default.
+	PolicyLatestStable = "latest-stable"
+	// PolicyPinned uses Version exactly; it must be available.
+	PolicyPinned = "pinned"
+	// PolicyMax picks the highest stable version not above Version.
+	// Build metadata after "+" is ignored unless Version has some.
+	PolicyMax = "max"
+)
+
+// SelectionPolicy says how to choose one component's version among the
+// candidates Fabric Meta or Maven offer.
+type SelectionPolicy struct {
+	Policy  string `yaml:"policy"`
+	Version string `yaml:"version"`
+}
+
+// Selection holds the policy for each resolved component. Zero values mean
+// latest-stable.
+type Selection struct {
+	Yarn      SelectionPolicy `yaml:"yarn"`
+	Loader    SelectionPolicy `yaml:"loader"`
+	FabricAPI SelectionPolicy `yaml:"fabric_api"`
+}
+
+// Validate checks every policy name and that pinned and max policies name
+// a version.
+func (s Selection) Validate() error {
+	for _, c := range []struct {
+		name string
+		p    SelectionPolicy
+	}{{"yarn", s.Yarn}, {"loader", s.Loader}, {"fabric_api", s.FabricAPI}} {
+		switch c.p.Policy {
+		case "", PolicyLatest, PolicyLatestStable:
+		case PolicyPinned, PolicyMax:
+			if c.p.Version == "" {
+				return fmt.Errorf("selection.%s: policy %q needs a version", c.name, c.p.Policy)
+			}
+		default:
+			return fmt.Errorf("selection.%s: unknown policy %q (want latest, latest-stable, pinned or max)", c.name, c.p.Policy)
+		}
+	}
+	return nil
+}
+
+// candidate is one published version of a component.
+type candidate struct {
+	Version string
+	Stable  bool
+}
+
+// selectVersion applies p to candidates. The candidates' order does not
+// matter; versions are compared with CompareVersions.
+func selectVersion(component string, candidates []candidate, p SelectionPolicy) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no %s versions available", component)
+	}
+	switch p.Policy {
+	case PolicyPinned:
+		for _, c := range candidates {
+			if c.Version == p.Version {
+				return c.Version, nil
+			}
+		}
+		return "", fmt.Errorf("pinned %s %s is not available", component, p.Version)
+	case PolicyMax:
+		// A bound without "+..." caps the build alone, so "0.103.0" admits
+		// 0.103.0+1.21.1.
+		hasMeta := strings.Contains(p.Version, "+")
+		best, ok := highest(candidates, func(c candidate) bool {
+			v := c.Version
+			if !hasMeta {
+				v, _, _ = strings.Cut(v, "+")
+			}
+			return c.Stable && CompareVersions(v, p.Version) <= 0
+		})
+		if !ok {
+			return "", fmt.Errorf("no stable %s version at or below %s", component, p.Version)
+		}
+		return best, nil
+	case PolicyLatest:
+		best, _ := highest(candidates, nil)
+		return best, nil
+	default:
+		if best, ok := highest(candidates, func(c candidate) bool { return c.Stable }); ok {
+			return best, nil
+		}
+		best, _ := highest(candidates, nil)
+		return best, nil
+	}
+}
+
+// highest returns the greatest candidate version accepted by keep (all
+// candidates when keep is nil).
+func highest(candidates []candidate, keep func(candidate) bool) (string, bool) {
+	best, found := "", false
+	for _, c := range candidates {
+		if keep != nil && !keep(c) {
+			continue
+		}
+		if !found || CompareVersions(c.Version, best) > 0 {
+			best, found = c.Version, true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,104 @@
+package mcgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The fixtures under testdata are trimmed Fabric Meta and Maven responses,
+// kept in their original shape and newest-first order.
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSelectFabricAPIVersion(t *testing.T) {
+	candidates, err := parseFabricAPIVersions(readFixture(t, "fabric-api-maven-metadata.xml"), "1.21.1")
+	if err != nil {
+		t.Fatalf("parseFabricAPIVersions error: %v", err)
+	}
+	if len(candidates) != 4 {
+		t.Fatalf("expected 4 candidates for 1.21.1, got %+v", candidates)
+	}
+
+	tests := []struct {
+		policy SelectionPolicy
+		want   string
+	}{
+		// sort.Strings used to pick 0.99.2 here.
+		{SelectionPolicy{}, "0.103.0+1.21.1"},
+		{SelectionPolicy{Policy: PolicyLatestStable}, "0.103.0+1.21.1"},
+		{SelectionPolicy{Policy: PolicyLatest}, "0.104.0-beta.1+1.21.1"},
+		{SelectionPolicy{Policy: PolicyPinned, Version: "0.100.0+1.21.1"}, "0.100.0+1.21.1"},
+		{SelectionPolicy{Policy: PolicyMax, Version: "0.102"}, "0.100.0+1.21.1"},
+		{SelectionPolicy{Policy: PolicyMax, Version: "0.103.0+1.21.1"}, "0.103.0+1.21.1"},
+		{SelectionPolicy{Policy: PolicyMax, Version: "0.103.0"}, "0.103.0+1.21.1"},
+	}
+	for _, tt := range tests {
+		got, err := selectVersion("fabric-api", candidates, tt.policy)
+		if err != nil {
+			t.Errorf("%+v: unexpected error %v", tt.policy, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.policy, got, tt.want)
+		}
+	}
+
+	// Snapshot game versions use the base version's builds.
+	snap, err := parseFabricAPIVersions(readFixture(t, "fabric-api-maven-metadata.xml"), "26.1-snapshot-1")
+	if err != nil || len(snap) != 1 || snap[0].Version != "0.141.0+26.1" {
+		t.Fatalf("unexpected 26.1 candidates %+v, %v", snap, err)
+	}
+	if _, err := parseFabricAPIVersions(readFixture(t, "fabric-api-maven-metadata.xml"), "1.19.2"); err == nil {
+		t.Fatalf("expected an error when no build matches")
+	}
+}
+
+func TestSelectYarnAndLoaderVersions(t *testing.T) {
+	yarn, err := parseYarnVersions(readFixture(t, "yarn-1.21.1.json"), "1.21.1")
+	if err != nil {
+		t.Fatalf("parseYarnVersions error: %v", err)
+	}
+	if got, _ := selectVersion("yarn", yarn, SelectionPolicy{}); got != "1.21.1+build.9" {
+		t.Fatalf("expected latest stable yarn build.9, got %s", got)
+	}
+	if got, _ := selectVersion("yarn", yarn, SelectionPolicy{Policy: PolicyLatest}); got != "1.21.1+build.10" {
+		t.Fatalf("expected latest yarn build.10, got %s", got)
+	}
+
+	loader, err := parseLoaderVersions(readFixture(t, "loader-1.21.1.json"), "1.21.1")
+	if err != nil {
+		t.Fatalf("parseLoaderVersions error: %v", err)
+	}
+	if got, _ := selectVersion("loader", loader, SelectionPolicy{}); got != "0.16.10" {
+		t.Fatalf("expected loader 0.16.10, got %s", got)
+	}
+	if got, _ := selectVersion("loader", loader, SelectionPolicy{Policy: PolicyMax, Version: "0.16.9"}); got != "0.16.9" {
+		t.Fatalf("expected loader 0.16.9 under the cap, got %s", got)
+	}
+	if _, err := selectVersion("loader", loader, SelectionPolicy{Policy: PolicyPinned, Version: "0.15.0"}); err == nil || !strings.Contains(err.Error(), "0.15.0") {
+		t.Fatalf("expected pinned miss to fail, got %v", err)
+	}
+	if _, err := selectVersion("loader", loader, SelectionPolicy{Policy: PolicyMax, Version: "0.1"}); err == nil {
+		t.Fatalf("expected an error when nothing is under the cap")
+	}
+}
+
+func TestSelectionValidate(t *testing.T) {
+	if err := (Selection{Loader: SelectionPolicy{Policy: PolicyLatest}}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (Selection{Yarn: SelectionPolicy{Policy: PolicyPinned}}).Validate(); err == nil {
+		t.Fatalf("expected pinned without version to fail")
+	}
+	if err := (Selection{FabricAPI: SelectionPolicy{Policy: "newest"}}).Validate(); err == nil || !strings.Contains(err.Error(), "fabric_api") {
+		t.Fatalf("expected unknown policy to fail, got %v", err)
+	}
+}
@@ -20,9 +20,51 @@ func TestParseMinecraftVersion(t *testing.T) {
 		{
 			name:    "snapshot version",
 			version: "26.1-snapshot-1",
-			want:    minecraftVersion{major: 26, minor: 1, patch: 0},
+			want:    minecraftVersion{major: 26, minor: 1, patch: 0, kind: kindSnapshot, build: 1},
+			wantErr: false,
+		},
+		{
+			name:    "pre-release",
+			version: "1.21.5-pre1",
+			want:    minecraftVersion{major: 1, minor: 21, patch: 5, kind: kindPreRelease, build: 1},
 			wantErr: false,
 		},
+		{
+			name:    "release candidate",
+			version: "26.1-rc-2",
+			want:    minecraftVersion{major: 26, minor: 1, patch: 0, kind: kindReleaseCandidate, build: 2},
+			wantErr: false,
+		},
+		{
+			name:    "weekly snapshot",
+			version: "25w14a",
+			want:    minecraftVersion{major: 1, minor: 21, patch: 5, kind: kindSnapshot, week: 2514, suffix: "a"},
+			wantErr: false,
+		},
+		{
+			name:    "weekly snapshot of a later release",
+			version: "25w41b",
+			want:    minecraftVersion{major: 1, minor: 21, patch: 11, kind: kindSnapshot, week: 2541, suffix: "b"},
+			wantErr: false,
+		},
+		{
+			name:    "weekly snapshot before the known cycles",
+			version: "22w11a",
+			want:    minecraftVersion{},
+			wantErr: true,
+		},
+		{
+			name:    "april fools release",
+			version: "24w14potato",
+			want:    minecraftVersion{},
+			wantErr: true,
+		},
+		{
+			name:    "unknown qualifier",
+			version: "1.21.5-beta1",
+			want:    minecraftVersion{},
+			wantErr: true,
+		},
 		{
 			name:    "two part version",
 			version: "26.1",
@@ -82,6 +124,21 @@ func TestNeedsYarnMappings(t *testing.T) {
 			version: "26.1-snapshot-1",
 			want:    false,
 		},
+		{
+			name:    "26.1-pre-1 does not need Yarn",
+			version: "26.1-pre-1",
+			want:    false,
+		},
+		{
+			name:    "1.21.5-pre1 needs Yarn",
+			version: "1.21.5-pre1",
+			want:    true,
+		},
+		{
+			name:    "weekly snapshot needs Yarn",
+			version: "25w45a",
+			want:    true,
+		},
 		{
 			name:    "26.2 does not need Yarn",
 			version: "26.2",
@@ -107,3 +164,58 @@ func TestNeedsYarnMappings(t *testing.T) {
 		})
 	}
 }
+
+func TestMinecraftVersionOrder(t *testing.T) {
+	// Each version sorts strictly after the one before it.
+	ordered := []string{
+		"1.21.4",
+		"25w02a",
+		"25w10a",
+		"25w10b",
+		"1.21.5-pre1",
+		"1.21.5-pre2",
+		"1.21.5-rc1",
+		"1.21.5",
+		"25w15a",
+		"1.21.6",
+		"1.21.10",
+		"25w41a",
+		"1.21.11-pre1",
+		"1.21.11",
+		"26.1-snapshot-1",
+		"26.1-snapshot-10",
+		"26.1-pre-1",
+		"26.1-rc-1",
+		"26.1",
+		"26.1.1",
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, err := parseMinecraftVersion(ordered[i-1])
+		if err != nil {
+			t.Fatalf("parse %s: %v", ordered[i-1], err)
+		}
+		b, err := parseMinecraftVersion(ordered[i])
+		if err != nil {
+			t.Fatalf("parse %s: %v", ordered[i], err)
+		}
+		if a.compare(b) >= 0 || b.compare(a) <= 0 {
+			t.Errorf("expected %s < %s", ordered[i-1], ordered[i])
+		}
+		if a.compare(a) != 0 {
+			t.Errorf("expected %s == itself", ordered[i-1])
+		}
+	}
+}
+
+func TestFabricAPIBaseVersion(t *testing.T) {
+	body := readFixture(t, "fabric-api-maven-metadata.xml")
+	for _, version := range []string{"1.21.4-pre1", "1.21.4-rc3", "24w46a"} {
+		got, err := parseFabricAPIVersions(body, version)
+		if err != nil || len(got) != 1 || got[0].Version != "0.116.0+1.21.4" {
+			t.Errorf("%s: expected the 1.21.4 build, got %+v, %v", version, got, err)
+		}
+	}
+	if _, err := parseFabricAPIVersions(body, "1.21.2-pre1"); err == nil {
+		t.Errorf("expected an error when the base version has no builds")
+	}
+}
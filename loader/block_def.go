@@ -0,0 +1,275 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BlockDef is the property schema of a single block: which properties it
+// has, which values each allows and which state is the default.
+type BlockDef struct {
+	ID string
+
+	props        []PropertyDef
+	index        map[string]int
+	defaultState map[string]string
+}
+
+// NewBlockDef builds a BlockDef from a property schema and default state.
+// defaultState may be nil when the data does not record one.
+func NewBlockDef(id string, props []PropertyDef, defaultState map[string]string) *BlockDef {
+	d := &BlockDef{
+		ID:    id,
+		props: make([]PropertyDef, len(props)),
+		index: make(map[string]int, len(props)),
+	}
+	for i, p := range props {
+		d.props[i] = PropertyDef{
+			Name:   p.Name,
+			Type:   p.Type,
+			Values: append([]string(nil), p.Values...),
+		}
+		d.index[p.Name] = i
+	}
+	if defaultState != nil {
+		d.defaultState = make(map[string]string, len(defaultState))
+		for k, v := range defaultState {
+			d.defaultState[k] = v
+		}
+	}
+	return d
+}
+
+// BlockDefFromFile builds a BlockDef from a per-block file. Files exported
+// before property_defs existed get a schema inferred from their states.
+func BlockDefFromFile(file BlockStatesFile) *BlockDef {
+	props := file.PropertyDefs
+	if len(props) == 0 {
+		props = inferPropertyDefs(file.States)
+	}
+	return NewBlockDef(file.BlockID, props, file.DefaultState)
+}
+
+// LoadBlockDefFile loads a single per-block JSON file and returns its BlockDef.
+func LoadBlockDefFile(path string) (*BlockDef, error) {
+	file, err := readBlocksFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return BlockDefFromFile(file), nil
+}
+
+// LoadBlockDefsDir scans a directory tree of per-block JSON files and
+// returns the block schemas keyed by block ID.
+func LoadBlockDefsDir(root string) (map[string]*BlockDef, error) {
+	out, err := LoadBlockDefsFS(os.DirFS(root), ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", root, err)
+	}
+	return out, nil
+}
+
+// LoadBlockDefsFS is LoadBlockDefsDir for any fs.FS.
+func LoadBlockDefsFS(fsys fs.FS, root string) (map[string]*BlockDef, error) {
+	paths, err := listJSONFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := decodeAll(paths, func(name string) (BlockStatesFile, error) {
+		return readBlocksFileFS(fsys, name)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]*BlockDef, len(files))
+	for _, f := range files {
+		out[f.BlockID] = BlockDefFromFile(f)
+	}
+	return out, nil
+}
+
+// Properties returns the block's property definitions in declaration order.
+func (d *BlockDef) Properties() []PropertyDef {
+	return d.props
+}
+
+// Property returns the definition of a single property by name.
+func (d *BlockDef) Property(name string) (PropertyDef, bool) {
+	i, ok := d.index[name]
+	if !ok {
+		return PropertyDef{}, false
+	}
+	return d.props[i], true
+}
+
+// DefaultState returns a copy of the block's default property values, or nil
+// if the data does not record a default.
+func (d *BlockDef) DefaultState() map[string]string {
+	if d.defaultState == nil {
+		return nil
+	}
+	out := make(map[string]string, len(d.defaultState))
+	for k, v := range d.defaultState {
+		out[k] = v
+	}
+	return out
+}
+
+// ValidateProps checks that every key in props is a property of this block
+// and that each value is allowed. Missing properties are not an error; use
+// CompleteProps to fill them in.
+func (d *BlockDef) ValidateProps(props map[string]string) error {
+	// Sorted so the reported error is deterministic.
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		def, ok := d.Property(k)
+		if !ok {
+			return fmt.Errorf("%s has no property %q", d.ID, k)
+		}
+		if !containsString(def.Values, props[k]) {
+			return fmt.Errorf("%s: invalid value %q for property %s (allowed: %s)",
+				d.ID, props[k], k, strings.Join(def.Values, ", "))
+		}
+	}
+	return nil
+}
+
+// CompleteProps validates a partial property map and fills in every missing
+// property from the default state.
+func (d *BlockDef) CompleteProps(partial map[string]string) (map[string]string, error) {
+	if err := d.ValidateProps(partial); err != nil {
+		return nil, err
+	}
+	out := make(map[string]string, len(d.props))
+	for _, p := range d.props {
+		if v, ok := partial[p.Name]; ok {
+			out[p.Name] = v
+			continue
+		}
+		v, ok := d.defaultState[p.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: property %s not set and no default state recorded", d.ID, p.Name)
+		}
+		out[p.Name] = v
+	}
+	return out, nil
+}
+
+// StateKey completes a partial property map and returns the StateKey that
+// identifies the resulting state in a LoadBlocksDir map.
+func (d *BlockDef) StateKey(partial map[string]string) (StateKey, error) {
+	props, err := d.CompleteProps(partial)
+	if err != nil {
+		return StateKey{}, err
+	}
+	return StateKey{BlockID: d.ID, PropsKey: MakePropsKey(props)}, nil
+}
+
+// EnumerateStates returns every combination of property values, with the
+// last property varying fastest. A block without properties has exactly one
+// (empty) state.
+func (d *BlockDef) EnumerateStates() []map[string]string {
+	n := 1
+	for _, p := range d.props {
+		n *= len(p.Values)
+	}
+	out := make([]map[string]string, 0, n)
+
+	var walk func(i int, cur map[string]string)
+	walk = func(i int, cur map[string]string) {
+		if i == len(d.props) {
+			state := make(map[string]string, len(cur))
+			for k, v := range cur {
+				state[k] = v
+			}
+			out = append(out, state)
+			return
+		}
+		p := d.props[i]
+		for _, v := range p.Values {
+			cur[p.Name] = v
+			walk(i+1, cur)
+		}
+		delete(cur, p.Name)
+	}
+	walk(0, make(map[string]string, len(d.props)))
+	return out
+}
+
+// inferPropertyDefs reconstructs a schema from the states of a block file.
+// Property names are sorted (as Minecraft orders them); booleans and ints
+// are ordered like the game does, enums keep first-seen order.
+func inferPropertyDefs(states []BlockStateRecordSlim) []PropertyDef {
+	values := make(map[string][]string)
+	for _, s := range states {
+		for k, v := range s.Properties {
+			if !containsString(values[k], v) {
+				values[k] = append(values[k], v)
+			}
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for k := range values {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	out := make([]PropertyDef, 0, len(names))
+	for _, name := range names {
+		vals := values[name]
+		def := PropertyDef{Name: name, Type: "enum", Values: vals}
+		switch {
+		case allBools(vals):
+			def.Type = "bool"
+			def.Values = []string{"true", "false"}
+		case allInts(vals):
+			def.Type = "int"
+			sort.Slice(vals, func(i, j int) bool {
+				a, _ := strconv.Atoi(vals[i])
+				b, _ := strconv.Atoi(vals[j])
+				return a < b
+			})
+		}
+		out = append(out, def)
+	}
+	return out
+}
+
+func allBools(vals []string) bool {
+	for _, v := range vals {
+		if v != "true" && v != "false" {
+			return false
+		}
+	}
+	return len(vals) > 0
+}
+
+func allInts(vals []string) bool {
+	for _, v := range vals {
+		if _, err := strconv.Atoi(v); err != nil {
+			return false
+		}
+	}
+	return len(vals) > 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package loader
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadBlockDefFile(t *testing.T) {
+	def, err := LoadBlockDefFile(filepath.Join("testdata", "blocks", "minecraft", "oak_slab.json"))
+	if err != nil {
+		t.Fatalf("LoadBlockDefFile error: %v", err)
+	}
+	if def.ID != "minecraft:oak_slab" {
+		t.Fatalf("expected ID minecraft:oak_slab, got %s", def.ID)
+	}
+	props := def.Properties()
+	if len(props) != 2 || props[0].Name != "type" || props[1].Name != "waterlogged" {
+		t.Fatalf("unexpected properties: %+v", props)
+	}
+	if props[1].Type != "bool" {
+		t.Fatalf("expected waterlogged to be bool, got %s", props[1].Type)
+	}
+	if got := def.DefaultState()["type"]; got != "bottom" {
+		t.Fatalf("expected default type bottom, got %q", got)
+	}
+
+	if err := def.ValidateProps(map[string]string{"type": "top"}); err != nil {
+		t.Fatalf("ValidateProps rejected valid props: %v", err)
+	}
+	if err := def.ValidateProps(map[string]string{"type": "sideways"}); err == nil {
+		t.Fatalf("expected error for invalid value")
+	}
+	if err := def.ValidateProps(map[string]string{"facing": "north"}); err == nil {
+		t.Fatalf("expected error for unknown property")
+	}
+
+	key, err := def.StateKey(map[string]string{"type": "top"})
+	if err != nil {
+		t.Fatalf("StateKey error: %v", err)
+	}
+	if key.PropsKey != "type=top,waterlogged=false" {
+		t.Fatalf("unexpected PropsKey %q", key.PropsKey)
+	}
+
+	states := def.EnumerateStates()
+	if len(states) != 6 {
+		t.Fatalf("expected 6 states, got %d", len(states))
+	}
+	if states[0]["type"] != "top" || states[0]["waterlogged"] != "true" {
+		t.Fatalf("unexpected first state: %v", states[0])
+	}
+	if states[1]["type"] != "top" || states[1]["waterlogged"] != "false" {
+		t.Fatalf("expected last property to vary fastest, got %v", states[1])
+	}
+}
+
+func TestLoadBlockDefsDirInfersSchema(t *testing.T) {
+	defs, err := LoadBlockDefsDir(filepath.Join("testdata", "blocks"))
+	if err != nil {
+		t.Fatalf("LoadBlockDefsDir error: %v", err)
+	}
+	dirt, ok := defs["minecraft:dirt"]
+	if !ok {
+		t.Fatalf("missing minecraft:dirt")
+	}
+	p, ok := dirt.Property("variant")
+	if !ok || p.Type != "enum" || len(p.Values) != 1 || p.Values[0] != "coarse" {
+		t.Fatalf("unexpected inferred property: %+v (ok=%v)", p, ok)
+	}
+	if dirt.DefaultState() != nil {
+		t.Fatalf("expected no default state for legacy file")
+	}
+	if _, err := dirt.CompleteProps(nil); err == nil {
+		t.Fatalf("expected error completing props without a default state")
+	}
+
+	stone := defs["minecraft:stone"]
+	if states := stone.EnumerateStates(); len(states) != 1 || len(states[0]) != 0 {
+		t.Fatalf("expected a single empty state for stone, got %v", states)
+	}
+}
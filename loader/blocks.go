@@ -11,15 +11,28 @@ type Box struct {
 	Max [3]float64 `json:"max"`
 }
 
+// PropertyDef describes one blockstate property: its name, kind
+// ("bool", "int" or "enum") and allowed values in declaration order.
+type PropertyDef struct {
+	Name   string   `json:"name"`
+	Type   string   `json:"type"`
+	Values []string `json:"values"`
+}
+
 // BlockStatesFile is the per-block file format.
 type BlockStatesFile struct {
-	BlockID    string                 `json:"block_id"`
-	Hardness   float64                `json:"hardness"`
-	Resistance float64                `json:"resistance"`
-	StackSize  int                    `json:"stack_size"`
-	Diggable   bool                   `json:"diggable"`
-	Material   []string               `json:"material"`
-	States     []BlockStateRecordSlim `json:"states"`
+	BlockID      string                 `json:"block_id"`
+	Hardness     float64                `json:"hardness"`
+	Resistance   float64                `json:"resistance"`
+	StackSize    int                    `json:"stack_size"`
+	Diggable     bool                   `json:"diggable"`
+	RequiresTool *bool                  `json:"requires_tool,omitempty"`
+	Material     []string               `json:"material"`
+	Tags         []string               `json:"tags,omitempty"`
+	ItemID       string                 `json:"item_id,omitempty"`
+	PropertyDefs []PropertyDef          `json:"property_defs,omitempty"`
+	DefaultState map[string]string      `json:"default_state,omitempty"`
+	States       []BlockStateRecordSlim `json:"states"`
 }
 
 // BlockStateRecord mirrors a single entry from blocks.json
@@ -27,6 +40,7 @@ type BlockStatesFile struct {
 type BlockStateRecord struct {
 	BlockID        string            `json:"block_id"`
 	Properties     map[string]string `json:"properties"`
+	StateID        int               `json:"state_id"`
 	CollisionBoxes []Box             `json:"collision_boxes"`
 	OutlineBoxes   []Box             `json:"outline_boxes"`
 	Air            bool              `json:"air"`
@@ -45,16 +59,23 @@ type BlockStateRecord struct {
 	Lava      bool `json:"lava"`
 	Fluid     bool `json:"fluid"`
 
-	Hardness   float64  `json:"hardness"`
-	Resistance float64  `json:"resistance"`
-	StackSize  int      `json:"stack_size"`
-	Diggable   bool     `json:"diggable"`
-	Material   []string `json:"material"`
+	Hardness     float64  `json:"hardness"`
+	Resistance   float64  `json:"resistance"`
+	StackSize    int      `json:"stack_size"`
+	Diggable     bool     `json:"diggable"`
+	RequiresTool *bool    `json:"requires_tool"`
+	Material     []string `json:"material"`
+	Tags         []string `json:"tags"`
+	ItemID       string   `json:"item_id"`
+
+	PropertyDefs []PropertyDef     `json:"property_defs"`
+	DefaultState map[string]string `json:"default_state"`
 }
 
 // BlockStateRecordSlim is used in per-block files (no BlockID).
 type BlockStateRecordSlim struct {
 	Properties     map[string]string `json:"properties"`
+	StateID        int               `json:"state_id"`
 	CollisionBoxes []Box             `json:"collision_boxes"`
 	OutlineBoxes   []Box             `json:"outline_boxes"`
 	Air            bool              `json:"air"`
@@ -82,6 +103,11 @@ type StateKey struct {
 
 // ShapeInfo is what you actually use at runtime in your RL env.
 type ShapeInfo struct {
+	// BlockID is the block this state belongs to, e.g. "minecraft:stone".
+	BlockID string
+	// StateID is the global block-state ID (the registry raw ID sent in
+	// chunk palettes and block update packets).
+	StateID        int
 	Collision      []Box
 	Outline        []Box
 	Air            bool
@@ -104,7 +130,16 @@ type ShapeInfo struct {
 	Resistance float64
 	StackSize  int
 	Diggable   bool
-	Material   []string
+	// RequiresTool is set when the block only drops (and breaks at full
+	// speed) with a correct tool. It is nil when the data doesn't say:
+	// exports before requires_tool only imply it through needs_*_tool tags.
+	RequiresTool *bool
+	// Material lists the block's mining tags: mineable/<tool> and
+	// needs_<tier>_tool.
+	Material []string
+	// Tags lists every block tag the block is in, e.g. "minecraft:logs",
+	// with nested tags resolved. Empty for data exported before tags were.
+	Tags []string
 }
 
 // MakePropsKey deterministically encodes properties as "k1=v1,k2=v2".
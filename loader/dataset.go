@@ -0,0 +1,210 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Dataset is everything generated for one Minecraft version, loaded in one
+// call: block states and schemas, items, entities, entity poses and tags.
+type Dataset struct {
+	Version   string
+	Blocks    map[StateKey]ShapeInfo
+	BlockDefs map[string]*BlockDef
+	Items     map[string]ItemInfo
+	Entities  map[string]EntityInfo
+	Poses     *PoseTable
+
+	// BlockTags, ItemTags and EntityTags come from tags.json. Versions
+	// exported without it get membership-only indexes built from the
+	// per-object tag lists, with no tag hierarchy.
+	BlockTags  *TagIndex
+	ItemTags   *TagIndex
+	EntityTags *TagIndex
+
+	blockItems map[string]string
+	itemBlocks map[string]string
+}
+
+// FileError records a single file (or directory) that failed to load.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// LoadErrors is the combined report of every file that failed while loading
+// a Dataset.
+type LoadErrors []FileError
+
+func (e LoadErrors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d file(s) failed to load:", len(e))
+	for _, fe := range e {
+		b.WriteString("\n  ")
+		b.WriteString(fe.Error())
+	}
+	return b.String()
+}
+
+// LoadDataset loads root/<version>/{blocks,items,entities,poses.json} and,
+// when present, tags.json.
+func LoadDataset(root, version string) (*Dataset, error) {
+	return LoadDatasetDir(filepath.Join(root, version))
+}
+
+// LoadDatasetDir loads a single version directory. Every file is attempted;
+// if any fail, the returned error is a LoadErrors listing all of them.
+func LoadDatasetDir(dir string) (*Dataset, error) {
+	ds, err := LoadDatasetFS(os.DirFS(dir), ".")
+	if err != nil {
+		var errs LoadErrors
+		if errors.As(err, &errs) {
+			for i := range errs {
+				errs[i].Path = filepath.Join(dir, filepath.FromSlash(errs[i].Path))
+			}
+			return nil, errs
+		}
+		return nil, err
+	}
+	ds.Version = filepath.Base(dir)
+	return ds, nil
+}
+
+// LoadDatasetFS is LoadDatasetDir for any fs.FS, with dir given as a
+// slash-separated path inside fsys. This allows loading a version embedded
+// with //go:embed or read straight out of a zip archive.
+func LoadDatasetFS(fsys fs.FS, dir string) (*Dataset, error) {
+	var errs LoadErrors
+
+	blockFiles := loadDatasetPart(fsys, path.Join(dir, "blocks"), readBlocksFileFS, &errs)
+	items := loadDatasetPart(fsys, path.Join(dir, "items"), LoadItemFileFS, &errs)
+	entities := loadDatasetPart(fsys, path.Join(dir, "entities"), LoadEntityFileFS, &errs)
+
+	posesPath := path.Join(dir, "poses.json")
+	poses, err := LoadPosesFS(fsys, posesPath)
+	if err != nil {
+		errs = append(errs, FileError{Path: posesPath, Err: err})
+	}
+
+	tagsPath := path.Join(dir, "tags.json")
+	tags, err := LoadTagsFS(fsys, tagsPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errs = append(errs, FileError{Path: tagsPath, Err: err})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	ds := &Dataset{
+		Version:    path.Base(dir),
+		BlockDefs:  make(map[string]*BlockDef, len(blockFiles)),
+		Items:      make(map[string]ItemInfo, len(items)),
+		Entities:   make(map[string]EntityInfo, len(entities)),
+		Poses:      poses,
+		blockItems: make(map[string]string),
+		itemBlocks: make(map[string]string),
+	}
+
+	n := 0
+	for _, f := range blockFiles {
+		n += len(f.States)
+	}
+	ds.Blocks = make(map[StateKey]ShapeInfo, n)
+	for _, f := range blockFiles {
+		addBlockStates(ds.Blocks, f)
+		ds.BlockDefs[f.BlockID] = BlockDefFromFile(f)
+	}
+	for _, it := range items {
+		ds.Items[it.ID] = it
+	}
+	for _, e := range entities {
+		ds.Entities[e.ID] = e
+	}
+	ds.BlockTags, ds.ItemTags, ds.EntityTags = tags["block"], tags["item"], tags["entity_type"]
+	if ds.BlockTags == nil {
+		m := make(map[string][]string, len(blockFiles))
+		for _, f := range blockFiles {
+			m[f.BlockID] = f.Tags
+		}
+		ds.BlockTags = TagIndexFromMembership(m)
+	}
+	if ds.ItemTags == nil {
+		m := make(map[string][]string, len(items))
+		for _, it := range items {
+			m[it.ID] = it.Tags
+		}
+		ds.ItemTags = TagIndexFromMembership(m)
+	}
+	if ds.EntityTags == nil {
+		m := make(map[string][]string, len(entities))
+		for _, e := range entities {
+			m[e.ID] = e.Tags
+		}
+		ds.EntityTags = TagIndexFromMembership(m)
+	}
+
+	// Prefer the exported item_id; older data falls back to matching IDs.
+	for _, f := range blockFiles {
+		itemID := f.ItemID
+		if itemID == "" {
+			itemID = f.BlockID
+		}
+		if _, ok := ds.Items[itemID]; !ok {
+			continue
+		}
+		ds.blockItems[f.BlockID] = itemID
+		if _, taken := ds.itemBlocks[itemID]; !taken || itemID == f.BlockID {
+			ds.itemBlocks[itemID] = f.BlockID
+		}
+	}
+
+	return ds, nil
+}
+
+// ItemForBlock returns the item a block is obtained/placed as, if any.
+func (ds *Dataset) ItemForBlock(blockID string) (string, bool) {
+	id, ok := ds.blockItems[blockID]
+	return id, ok
+}
+
+// BlockForItem returns the block an item places, if any.
+func (ds *Dataset) BlockForItem(itemID string) (string, bool) {
+	id, ok := ds.itemBlocks[itemID]
+	return id, ok
+}
+
+// loadDatasetPart decodes every JSON file under dir, appending failures to
+// errs instead of stopping at the first one.
+func loadDatasetPart[T any](fsys fs.FS, dir string, decode func(fs.FS, string) (T, error), errs *LoadErrors) []T {
+	paths, err := listJSONFiles(fsys, dir)
+	if err != nil {
+		*errs = append(*errs, FileError{Path: dir, Err: err})
+		return nil
+	}
+	results, fileErrs := decodeEach(paths, func(name string) (T, error) {
+		return decode(fsys, name)
+	})
+	out := make([]T, 0, len(results))
+	for i, r := range results {
+		if fileErrs[i] != nil {
+			*errs = append(*errs, FileError{Path: paths[i], Err: fileErrs[i]})
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
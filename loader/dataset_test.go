@@ -0,0 +1,121 @@
+package loader
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadDataset(t *testing.T) {
+	ds, err := LoadDataset(".", "testdata")
+	if err != nil {
+		t.Fatalf("LoadDataset error: %v", err)
+	}
+	if ds.Version != "testdata" {
+		t.Fatalf("expected version testdata, got %s", ds.Version)
+	}
+	if _, ok := ds.Blocks[StateKey{BlockID: "minecraft:stone"}]; !ok {
+		t.Fatalf("missing stone block state")
+	}
+	if _, ok := ds.BlockDefs["minecraft:oak_slab"]; !ok {
+		t.Fatalf("missing oak_slab block def")
+	}
+	if _, ok := ds.Items["minecraft:apple"]; !ok {
+		t.Fatalf("missing apple item")
+	}
+	if _, ok := ds.Entities["minecraft:zombie"]; !ok {
+		t.Fatalf("missing zombie entity")
+	}
+	if name, _ := ds.Poses.ByOrdinal(5); name != "crouching" {
+		t.Fatalf("expected pose 5 to be crouching, got %q", name)
+	}
+
+	if item, ok := ds.ItemForBlock("minecraft:stone"); !ok || item != "minecraft:stone" {
+		t.Fatalf("expected stone block to link to stone item, got %q (ok=%v)", item, ok)
+	}
+	if block, ok := ds.BlockForItem("minecraft:stone"); !ok || block != "minecraft:stone" {
+		t.Fatalf("expected stone item to link to stone block, got %q (ok=%v)", block, ok)
+	}
+	if _, ok := ds.ItemForBlock("minecraft:oak_slab"); ok {
+		t.Fatalf("expected no link for oak_slab, whose item is not in the dataset")
+	}
+	if _, ok := ds.BlockForItem("minecraft:apple"); ok {
+		t.Fatalf("expected no block for apple")
+	}
+
+	if !ds.BlockTags.Has("minecraft:stone", "#minecraft:stone_ore_replaceables") {
+		t.Fatalf("expected stone in stone_ore_replaceables")
+	}
+	if got := ds.BlockTags.Children("minecraft:mineable/shovel"); len(got) != 1 || got[0] != "minecraft:dirt" {
+		t.Fatalf("expected mineable/shovel to include #dirt, got %v", got)
+	}
+	if !ds.ItemTags.Has("minecraft:iron_sword", "minecraft:swords") || !ds.EntityTags.Has("minecraft:zombie", "minecraft:undead") {
+		t.Fatalf("missing item or entity tags")
+	}
+	if stone := ds.Blocks[StateKey{BlockID: "minecraft:stone"}]; !stone.HasTag("minecraft:base_stone_overworld") {
+		t.Fatalf("expected stone state to carry its block tags")
+	}
+}
+
+func TestLoadDatasetWithoutTagsFile(t *testing.T) {
+	// Everything in testdata except tags.json, as older exports look.
+	fsys := fstest.MapFS{}
+	err := fs.WalkDir(os.DirFS("testdata"), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || name == "tags.json" {
+			return err
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", filepath.FromSlash(name)))
+		fsys[name] = &fstest.MapFile{Data: data}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ds, err := LoadDatasetFS(fsys, ".")
+	if err != nil {
+		t.Fatalf("LoadDatasetFS error: %v", err)
+	}
+	// Membership still comes from the per-object tag lists.
+	if !ds.BlockTags.Has("minecraft:stone", "minecraft:stone_ore_replaceables") {
+		t.Fatalf("expected block membership from block files")
+	}
+	if !ds.ItemTags.Has("minecraft:iron_sword", "minecraft:swords") {
+		t.Fatalf("expected item membership from item files")
+	}
+	if got := ds.BlockTags.Children("minecraft:stone_ore_replaceables"); len(got) != 0 {
+		t.Fatalf("expected no hierarchy without tags.json, got %v", got)
+	}
+}
+
+func TestLoadDatasetReportsAllFailures(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{
+		filepath.Join("blocks", "minecraft", "bad.json"),
+		filepath.Join("items", "minecraft", "bad.json"),
+	} {
+		full := filepath.Join(dir, p)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("{not json"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := LoadDatasetDir(dir)
+	var loadErrs LoadErrors
+	if !errors.As(err, &loadErrs) {
+		t.Fatalf("expected LoadErrors, got %v", err)
+	}
+	// bad block file, bad item file, missing entities dir, missing poses.json
+	if len(loadErrs) != 4 {
+		t.Fatalf("expected 4 failures, got %d: %v", len(loadErrs), err)
+	}
+	if want := filepath.Join(dir, "blocks", "minecraft", "bad.json"); loadErrs[0].Path != want {
+		t.Fatalf("expected first failure path %s, got %s", want, loadErrs[0].Path)
+	}
+}
@@ -3,9 +3,8 @@ package loader
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
-	"strings"
 )
 
 // LoadEntityFile loads a single per-entity JSON file and returns an EntityInfo.
@@ -14,10 +13,22 @@ func LoadEntityFile(path string) (EntityInfo, error) {
 	if err != nil {
 		return EntityInfo{}, fmt.Errorf("read %s: %w", path, err)
 	}
+	return decodeEntityFile(path, data)
+}
+
+// LoadEntityFileFS is LoadEntityFile for a file inside an fs.FS.
+func LoadEntityFileFS(fsys fs.FS, name string) (EntityInfo, error) {
+	data, err := readFileFS(fsys, name)
+	if err != nil {
+		return EntityInfo{}, err
+	}
+	return decodeEntityFile(name, data)
+}
 
+func decodeEntityFile(name string, data []byte) (EntityInfo, error) {
 	var file EntityFile
 	if err := json.Unmarshal(data, &file); err != nil {
-		return EntityInfo{}, fmt.Errorf("unmarshal %s: %w", path, err)
+		return EntityInfo{}, fmt.Errorf("unmarshal %s: %w", name, err)
 	}
 
 	info := EntityInfo{
@@ -48,28 +59,31 @@ func MergeEntityMaps(maps ...map[string]EntityInfo) map[string]EntityInfo {
 // LoadEntitiesDir scans a directory tree of per-entity JSON files
 // (grouped by namespace) and returns a map keyed by entity ID.
 func LoadEntitiesDir(root string) (map[string]EntityInfo, error) {
-	out := make(map[string]EntityInfo)
+	out, err := LoadEntitiesFS(os.DirFS(root), ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", root, err)
+	}
+	return out, nil
+}
 
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(d.Name(), ".json") {
-			return nil
-		}
+// LoadEntitiesFS is LoadEntitiesDir for any fs.FS, with root given as a
+// slash-separated path inside fsys.
+func LoadEntitiesFS(fsys fs.FS, root string) (map[string]EntityInfo, error) {
+	paths, err := listJSONFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
 
-		info, err := LoadEntityFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to load file %s: %w", path, err)
-		}
-		out[info.ID] = info
-		return nil
+	infos, err := decodeAll(paths, func(name string) (EntityInfo, error) {
+		return LoadEntityFileFS(fsys, name)
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	out := make(map[string]EntityInfo, len(infos))
+	for _, info := range infos {
+		out[info.ID] = info
+	}
 	return out, nil
 }
@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"embed"
+	"io/fs"
+	"path"
+	"testing"
+)
+
+//go:embed testdata
+var testdataFS embed.FS
+
+func TestLoadFromEmbedFS(t *testing.T) {
+	blocks, err := LoadBlocksFS(testdataFS, "testdata/blocks")
+	if err != nil {
+		t.Fatalf("LoadBlocksFS error: %v", err)
+	}
+	if _, ok := blocks[StateKey{BlockID: "minecraft:stone"}]; !ok {
+		t.Fatalf("missing stone block state")
+	}
+
+	items, err := LoadItemsFS(testdataFS, "testdata/items")
+	if err != nil {
+		t.Fatalf("LoadItemsFS error: %v", err)
+	}
+	if _, ok := items["minecraft:iron_sword"]; !ok {
+		t.Fatalf("missing iron_sword item")
+	}
+
+	entities, err := LoadEntitiesFS(testdataFS, "testdata/entities")
+	if err != nil {
+		t.Fatalf("LoadEntitiesFS error: %v", err)
+	}
+	if _, ok := entities["minecraft:slime"]; !ok {
+		t.Fatalf("missing slime entity")
+	}
+}
+
+func TestLoadDatasetFromZip(t *testing.T) {
+	// Re-pack testdata under a version directory inside an in-memory zip.
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	err := fs.WalkDir(testdataFS, "testdata", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(testdataFS, p)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(path.Join("1.21.5", p[len("testdata/"):]))
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("build zip: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	ds, err := LoadDatasetFS(zr, "1.21.5")
+	if err != nil {
+		t.Fatalf("LoadDatasetFS error: %v", err)
+	}
+	if ds.Version != "1.21.5" {
+		t.Fatalf("expected version 1.21.5, got %s", ds.Version)
+	}
+	if _, ok := ds.Entities["minecraft:zombie"]; !ok {
+		t.Fatalf("missing zombie entity")
+	}
+	if _, ok := ds.Poses.Ordinal("sleeping"); !ok {
+		t.Fatalf("missing sleeping pose")
+	}
+}
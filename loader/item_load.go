@@ -3,9 +3,8 @@ package loader
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
-	"strings"
 )
 
 // LoadItemFile loads a single per-item JSON file and returns an ItemInfo.
@@ -14,10 +13,22 @@ func LoadItemFile(path string) (ItemInfo, error) {
 	if err != nil {
 		return ItemInfo{}, fmt.Errorf("read %s: %w", path, err)
 	}
+	return decodeItemFile(path, data)
+}
+
+// LoadItemFileFS is LoadItemFile for a file inside an fs.FS.
+func LoadItemFileFS(fsys fs.FS, name string) (ItemInfo, error) {
+	data, err := readFileFS(fsys, name)
+	if err != nil {
+		return ItemInfo{}, err
+	}
+	return decodeItemFile(name, data)
+}
 
+func decodeItemFile(name string, data []byte) (ItemInfo, error) {
 	var file ItemFile
 	if err := json.Unmarshal(data, &file); err != nil {
-		return ItemInfo{}, fmt.Errorf("unmarshal %s: %w", path, err)
+		return ItemInfo{}, fmt.Errorf("unmarshal %s: %w", name, err)
 	}
 
 	return ItemInfo{
@@ -48,28 +59,31 @@ func MergeItemsMaps(maps ...map[string]ItemInfo) map[string]ItemInfo {
 // LoadItemsDir scans a directory tree of per-item JSON files
 // (grouped by namespace) and returns a map keyed by item ID.
 func LoadItemsDir(root string) (map[string]ItemInfo, error) {
-	out := make(map[string]ItemInfo)
+	out, err := LoadItemsFS(os.DirFS(root), ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", root, err)
+	}
+	return out, nil
+}
 
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(d.Name(), ".json") {
-			return nil
-		}
+// LoadItemsFS is LoadItemsDir for any fs.FS, with root given as a
+// slash-separated path inside fsys.
+func LoadItemsFS(fsys fs.FS, root string) (map[string]ItemInfo, error) {
+	paths, err := listJSONFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
 
-		info, err := LoadItemFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to load file %s: %w", path, err)
-		}
-		out[info.ID] = info
-		return nil
+	infos, err := decodeAll(paths, func(name string) (ItemInfo, error) {
+		return LoadItemFileFS(fsys, name)
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	out := make(map[string]ItemInfo, len(infos))
+	for _, info := range infos {
+		out[info.ID] = info
+	}
 	return out, nil
 }
@@ -34,6 +34,7 @@ func TestLoadItemsDir(t *testing.T) {
 	wantItems := []string{
 		"minecraft:iron_sword",
 		"minecraft:apple",
+		"minecraft:stone",
 	}
 	for _, id := range wantItems {
 		if _, ok := m[id]; !ok {
@@ -14,6 +14,7 @@ type ItemComponents struct {
 	Damage         *int            `json:"damage,omitempty"`
 	Enchantments   []any           `json:"enchantments,omitempty"`
 	IsTool         *bool           `json:"is_tool,omitempty"`
+	Tool           *ToolComponent  `json:"tool,omitempty"`
 	Food           *FoodComponent  `json:"food,omitempty"`
 }
 
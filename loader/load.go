@@ -3,30 +3,58 @@ package loader
 import (
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
-	"path/filepath"
 	"strings"
 )
 
 // LoadBlocksFile loads a single per-block JSON file and returns a map keyed by StateKey.
 func LoadBlocksFile(path string) (map[StateKey]ShapeInfo, error) {
 	out := make(map[StateKey]ShapeInfo)
+	file, err := readBlocksFile(path)
+	if err != nil {
+		return out, err
+	}
+	addBlockStates(out, file)
+	return out, nil
+}
+
+// readBlocksFile reads and decodes one per-block JSON file.
+func readBlocksFile(path string) (BlockStatesFile, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return out, fmt.Errorf("read %s: %w", path, err)
+		return BlockStatesFile{}, fmt.Errorf("read %s: %w", path, err)
+	}
+	return decodeBlocksFile(path, data)
+}
+
+// readBlocksFileFS is readBlocksFile for a file inside an fs.FS.
+func readBlocksFileFS(fsys fs.FS, name string) (BlockStatesFile, error) {
+	data, err := readFileFS(fsys, name)
+	if err != nil {
+		return BlockStatesFile{}, err
 	}
+	return decodeBlocksFile(name, data)
+}
 
+func decodeBlocksFile(name string, data []byte) (BlockStatesFile, error) {
 	var file BlockStatesFile
 	if err := json.Unmarshal(data, &file); err != nil {
-		return out, fmt.Errorf("unmarshal %s: %w", path, err)
+		return BlockStatesFile{}, fmt.Errorf("unmarshal %s: %w", name, err)
 	}
+	return file, nil
+}
 
+// addBlockStates inserts every state of a decoded block file into out.
+func addBlockStates(out map[StateKey]ShapeInfo, file BlockStatesFile) {
 	for _, s := range file.States {
 		key := StateKey{
 			BlockID:  file.BlockID,
 			PropsKey: MakePropsKey(s.Properties),
 		}
 		out[key] = ShapeInfo{
+			BlockID:        file.BlockID,
+			StateID:        s.StateID,
 			Collision:      append([]Box(nil), s.CollisionBoxes...),
 			Outline:        append([]Box(nil), s.OutlineBoxes...),
 			Air:            s.Air,
@@ -47,11 +75,33 @@ func LoadBlocksFile(path string) (map[StateKey]ShapeInfo, error) {
 			Resistance:     file.Resistance,
 			StackSize:      file.StackSize,
 			Diggable:       file.Diggable,
+			RequiresTool:   requiresTool(file),
 			Material:       file.Material,
+			Tags:           file.Tags,
 		}
 	}
+}
 
-	return out, nil
+// requiresTool returns the exported requires_tool flag. Files exported
+// before it existed only know it for tier-gated blocks (a needs_*_tool
+// tag); for every other block it is unknown and nil is returned, since
+// mineable/* alone says nothing about drops (stone needs a pickaxe, ice and
+// stone buttons don't). Regenerate the data for exact values.
+func requiresTool(file BlockStatesFile) *bool {
+	if file.RequiresTool != nil {
+		v := *file.RequiresTool
+		return &v
+	}
+	for _, tags := range [][]string{file.Material, file.Tags} {
+		for _, t := range tags {
+			t = strings.TrimPrefix(t, "minecraft:")
+			if strings.HasPrefix(t, "needs_") && strings.HasSuffix(t, "_tool") {
+				v := true
+				return &v
+			}
+		}
+	}
+	return nil
 }
 
 // MergeBlocksMaps merges multiple version maps, preferring later entries when keys collide.
@@ -68,27 +118,37 @@ func MergeBlocksMaps(maps ...map[StateKey]ShapeInfo) map[StateKey]ShapeInfo {
 // LoadBlocksDir scans a directory tree of per-block JSON files
 // (grouped by namespace) and returns the same map[StateKey]ShapeInfo.
 func LoadBlocksDir(root string) (map[StateKey]ShapeInfo, error) {
-	out := make(map[StateKey]ShapeInfo)
+	out, err := LoadBlocksFS(os.DirFS(root), ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", root, err)
+	}
+	return out, nil
+}
 
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			return nil
-		}
-		if !strings.HasSuffix(d.Name(), ".json") {
-			return nil
-		}
-		tmpOut, err := LoadBlocksFile(path)
-		if err != nil {
-			return fmt.Errorf("failed to load file %s: %s", path, err.Error())
-		}
-		out = MergeBlocksMaps(out, tmpOut)
-		return nil
+// LoadBlocksFS is LoadBlocksDir for any fs.FS (embed.FS, zip.Reader, ...),
+// with root given as a slash-separated path inside fsys.
+// Files are decoded concurrently and inserted in a single pass, in walk
+// order, so later files still win on key collisions.
+func LoadBlocksFS(fsys fs.FS, root string) (map[StateKey]ShapeInfo, error) {
+	paths, err := listJSONFiles(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := decodeAll(paths, func(name string) (BlockStatesFile, error) {
+		return readBlocksFileFS(fsys, name)
 	})
 	if err != nil {
 		return nil, err
 	}
+
+	n := 0
+	for _, f := range files {
+		n += len(f.States)
+	}
+	out := make(map[StateKey]ShapeInfo, n)
+	for _, f := range files {
+		addBlockStates(out, f)
+	}
 	return out, nil
 }
@@ -0,0 +1,27 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchDataDir points at one version of the checked-in data tree at the
+// repository root (the loader module lives one level below it).
+var benchDataDir = filepath.Join("..", "data", "1.21.11", "blocks")
+
+func BenchmarkLoadBlocksDir(b *testing.B) {
+	if _, err := os.Stat(benchDataDir); err != nil {
+		b.Skipf("benchmark data not available: %v", err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m, err := LoadBlocksDir(benchDataDir)
+		if err != nil {
+			b.Fatalf("LoadBlocksDir error: %v", err)
+		}
+		if len(m) == 0 {
+			b.Fatalf("no block states loaded from %s", benchDataDir)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package loader
 
 import (
+    "os"
     "path/filepath"
     "testing"
 
@@ -26,9 +27,38 @@ func TestLoadBlocksFile(t *testing.T) {
     assert.Equal(t, 6.0, info.Resistance)
     assert.Equal(t, 64, info.StackSize)
     assert.True(t, info.Diggable)
+    require.NotNil(t, info.RequiresTool)
+    assert.True(t, *info.RequiresTool)
     assert.Equal(t, []string{"mineable/pickaxe"}, info.Material)
 }
 
+func TestRequiresToolFallback(t *testing.T) {
+    no := false
+    assert.Equal(t, &no, requiresTool(BlockStatesFile{RequiresTool: &no, Material: []string{"mineable/pickaxe"}}))
+
+    // Older exports have no requires_tool; only the needs_*_tool tags are
+    // trusted. Pickaxe-mineable alone (stone vs ice) leaves it unknown.
+    yes := true
+    assert.Nil(t, requiresTool(BlockStatesFile{Material: []string{"mineable/pickaxe"}}))
+    assert.Equal(t, &yes, requiresTool(BlockStatesFile{Material: []string{"mineable/pickaxe", "needs_stone_tool"}}))
+    assert.Equal(t, &yes, requiresTool(BlockStatesFile{Tags: []string{"minecraft:needs_iron_tool"}}))
+    assert.Nil(t, requiresTool(BlockStatesFile{Material: []string{"mineable/shovel"}}))
+}
+
+func TestRequiresToolUnknownInShippedData(t *testing.T) {
+    // The checked-in data predates requires_tool.
+    path := filepath.Join("..", "data", "1.21.6", "blocks", "minecraft", "stone.json")
+    if _, err := os.Stat(path); err != nil {
+        t.Skipf("shipped data not available: %v", err)
+    }
+    m, err := LoadBlocksFile(path)
+    require.NoError(t, err)
+    info, ok := m[StateKey{BlockID: "minecraft:stone"}]
+    require.True(t, ok)
+    assert.Equal(t, []string{"mineable/pickaxe"}, info.Material)
+    assert.Nil(t, info.RequiresTool, "stone must not be reported as breakable by hand at full speed")
+}
+
 func TestLoadBlocksDir(t *testing.T) {
     root := filepath.Join("testdata", "blocks")
     m, err := LoadBlocksDir(root)
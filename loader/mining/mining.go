@@ -0,0 +1,215 @@
+// Package mining computes how long it takes to break a block, following
+// vanilla's PlayerEntity.getBlockBreakingSpeed and
+// AbstractBlock.calcBlockBreakingDelta.
+package mining
+
+import (
+	"errors"
+	"math"
+	"strings"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+// Modifiers are the player-side factors that change breaking speed. The
+// zero value is a player standing on the ground, out of water, with no
+// effects or enchantments.
+type Modifiers struct {
+	// Efficiency is the level of the Efficiency enchantment on the held tool.
+	Efficiency int
+	// AquaAffinity cancels the underwater penalty.
+	AquaAffinity bool
+
+	// Haste is the Haste (or Conduit Power) effect level; 1 is Haste I.
+	Haste int
+	// MiningFatigue is the Mining Fatigue effect level; 1 is Mining Fatigue I.
+	MiningFatigue int
+
+	// Underwater is set when the player's eyes are submerged in water.
+	Underwater bool
+	// Airborne is set when the player is not on the ground (flying,
+	// swimming, falling or climbing).
+	Airborne bool
+
+	// MiningEfficiency is any extra minecraft:mining_efficiency attribute
+	// on top of what Efficiency grants.
+	MiningEfficiency float64
+	// BlockBreakSpeed is the minecraft:block_break_speed attribute; 0 means
+	// the default of 1.
+	BlockBreakSpeed float64
+}
+
+// ErrRequiresToolUnknown is returned when the answer depends on whether a
+// block needs a tool for drops and the data doesn't say. Data exported
+// before requires_tool only records it for blocks with a needs_*_tool tag.
+var ErrRequiresToolUnknown = errors.New("mining: block data has no requires_tool; regenerate it")
+
+// BreakTicks returns how many game ticks it takes to break state with
+// heldItem (nil for an empty hand). 0 means the block breaks instantly.
+// ok is false for unbreakable blocks such as bedrock.
+func BreakTicks(state loader.ShapeInfo, heldItem *loader.ItemInfo, mods Modifiers) (ticks int, ok bool, err error) {
+	delta, err := BreakDelta(state, heldItem, mods)
+	switch {
+	case err != nil:
+		return 0, false, err
+	case state.Hardness < 0 || delta <= 0:
+		return 0, false, nil
+	case delta >= 1:
+		return 0, true, nil
+	}
+	// The game adds delta to a progress counter every tick and breaks the
+	// block once it reaches 1.
+	return int(math.Ceil(1/delta - 1e-9)), true, nil
+}
+
+// BreakDelta returns the fraction of the block broken per tick.
+func BreakDelta(state loader.ShapeInfo, heldItem *loader.ItemInfo, mods Modifiers) (float64, error) {
+	if state.Hardness < 0 {
+		return 0, nil
+	}
+	speed, correct := ToolSpeed(state, heldItem)
+	speed = applyModifiers(speed, mods)
+	if state.Hardness == 0 {
+		return math.Inf(1), nil
+	}
+	// Without the correct tool the game slows breaking down only for
+	// blocks that require one.
+	div := 30.0
+	if !correct {
+		required, err := requiresTool(state)
+		if err != nil {
+			return 0, err
+		}
+		if required {
+			div = 100
+		}
+	}
+	return speed / state.Hardness / div, nil
+}
+
+// CanHarvest reports whether breaking state with heldItem yields drops.
+func CanHarvest(state loader.ShapeInfo, heldItem *loader.ItemInfo) (bool, error) {
+	if _, correct := ToolSpeed(state, heldItem); correct {
+		return true, nil
+	}
+	required, err := requiresTool(state)
+	if err != nil {
+		return false, err
+	}
+	return !required, nil
+}
+
+func requiresTool(state loader.ShapeInfo) (bool, error) {
+	if state.RequiresTool == nil {
+		return false, ErrRequiresToolUnknown
+	}
+	return *state.RequiresTool, nil
+}
+
+// applyModifiers mirrors getBlockBreakingSpeed after the tool's own speed.
+func applyModifiers(speed float64, mods Modifiers) float64 {
+	if speed > 1 {
+		bonus := mods.MiningEfficiency
+		if mods.Efficiency > 0 {
+			bonus += float64(mods.Efficiency*mods.Efficiency + 1)
+		}
+		speed += bonus
+	}
+	if mods.Haste > 0 {
+		speed *= 1 + float64(mods.Haste)*0.2
+	}
+	if mods.MiningFatigue > 0 {
+		switch mods.MiningFatigue {
+		case 1:
+			speed *= 0.3
+		case 2:
+			speed *= 0.09
+		case 3:
+			speed *= 0.0027
+		default:
+			speed *= 8.1e-4
+		}
+	}
+	if mods.BlockBreakSpeed != 0 {
+		speed *= mods.BlockBreakSpeed
+	}
+	if mods.Underwater && !mods.AquaAffinity {
+		speed *= 0.2
+	}
+	if mods.Airborne {
+		speed /= 5
+	}
+	return speed
+}
+
+// ToolSpeed returns heldItem's mining speed against state and whether it
+// is the correct tool for drops, from the item's tool component.
+func ToolSpeed(state loader.ShapeInfo, heldItem *loader.ItemInfo) (speed float64, correct bool) {
+	if heldItem == nil {
+		return 1, false
+	}
+	if tool := heldItem.Components.Tool; tool != nil {
+		return tool.MiningSpeed(state), tool.CorrectForDrops(state)
+	}
+	return guessToolSpeed(state, heldItem)
+}
+
+// tierSpeed and tierLevel describe the vanilla tool materials. Levels
+// follow the needs_<tier>_tool tags: 1 stone, 2 iron, 3 diamond.
+var (
+	tierSpeed = map[string]float64{
+		"wooden": 2, "stone": 4, "copper": 5, "iron": 6,
+		"diamond": 8, "netherite": 9, "golden": 12,
+	}
+	tierLevel = map[string]int{
+		"wooden": 0, "golden": 0, "stone": 1, "copper": 1,
+		"iron": 2, "diamond": 3, "netherite": 4,
+	}
+	toolTags = map[string]string{
+		"minecraft:pickaxes": "pickaxe",
+		"minecraft:axes":     "axe",
+		"minecraft:shovels":  "shovel",
+		"minecraft:hoes":     "hoe",
+	}
+)
+
+// guessToolSpeed covers items exported before the tool component was: the
+// tool's kind comes from its item tags and its tier from its ID, which is
+// right for the vanilla pickaxes, axes, shovels and hoes.
+func guessToolSpeed(state loader.ShapeInfo, heldItem *loader.ItemInfo) (speed float64, correct bool) {
+	kind := ""
+	for _, t := range heldItem.Tags {
+		if k, ok := toolTags[t]; ok {
+			kind = k
+			break
+		}
+	}
+	if kind == "" || !state.HasTag("mineable/"+kind) {
+		return 1, false
+	}
+
+	name := heldItem.ID[strings.IndexByte(heldItem.ID, ':')+1:]
+	tier := name
+	if i := strings.IndexByte(name, '_'); i >= 0 {
+		tier = name[:i]
+	}
+	s, ok := tierSpeed[tier]
+	if !ok {
+		return 1, false
+	}
+	return s, tierLevel[tier] >= requiredLevel(state)
+}
+
+// requiredLevel is the minimum tier level the block's needs_* tags demand.
+func requiredLevel(state loader.ShapeInfo) int {
+	switch {
+	case state.HasTag("needs_diamond_tool"):
+		return 3
+	case state.HasTag("needs_iron_tool"):
+		return 2
+	case state.HasTag("needs_stone_tool"):
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,207 @@
+package mining
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+var (
+	required, notRequired = true, false
+
+	stone = loader.ShapeInfo{
+		Hardness: 1.5, Diggable: true, RequiresTool: &required,
+		Material: []string{"mineable/pickaxe"},
+	}
+	ironOre = loader.ShapeInfo{
+		Hardness: 3, Diggable: true, RequiresTool: &required,
+		Material: []string{"mineable/pickaxe", "needs_stone_tool"},
+	}
+	obsidian = loader.ShapeInfo{
+		Hardness: 50, Diggable: true, RequiresTool: &required,
+		Material: []string{"mineable/pickaxe", "needs_diamond_tool"},
+	}
+	dirt      = loader.ShapeInfo{Hardness: 0.5, Diggable: true, RequiresTool: &notRequired, Material: []string{"mineable/shovel"}}
+	shortGras = loader.ShapeInfo{Hardness: 0, Diggable: true}
+	bedrock   = loader.ShapeInfo{Hardness: -1}
+)
+
+func tool(id, tag string) *loader.ItemInfo {
+	return &loader.ItemInfo{ID: id, Tags: []string{tag, "minecraft:enchantable/mining"}}
+}
+
+var (
+	woodenPickaxe  = tool("minecraft:wooden_pickaxe", "minecraft:pickaxes")
+	stonePickaxe   = tool("minecraft:stone_pickaxe", "minecraft:pickaxes")
+	ironPickaxe    = tool("minecraft:iron_pickaxe", "minecraft:pickaxes")
+	diamondPickaxe = tool("minecraft:diamond_pickaxe", "minecraft:pickaxes")
+	diamondShovel  = tool("minecraft:diamond_shovel", "minecraft:shovels")
+	goldenPickaxe  = tool("minecraft:golden_pickaxe", "minecraft:pickaxes")
+)
+
+// Expected values are the break times listed on the Minecraft wiki.
+func TestBreakTicks(t *testing.T) {
+	cases := []struct {
+		name  string
+		state loader.ShapeInfo
+		item  *loader.ItemInfo
+		mods  Modifiers
+		want  int
+	}{
+		{"stone by hand", stone, nil, Modifiers{}, 150},
+		{"stone wooden pickaxe", stone, woodenPickaxe, Modifiers{}, 23},
+		{"stone diamond shovel", stone, diamondShovel, Modifiers{}, 150},
+		{"stone golden pickaxe", stone, goldenPickaxe, Modifiers{}, 4},
+		{"iron ore wooden pickaxe", ironOre, woodenPickaxe, Modifiers{}, 150},
+		{"iron ore stone pickaxe", ironOre, stonePickaxe, Modifiers{}, 23},
+		{"obsidian diamond pickaxe", obsidian, diamondPickaxe, Modifiers{}, 188},
+		{"obsidian iron pickaxe", obsidian, ironPickaxe, Modifiers{}, 834},
+		{"dirt by hand", dirt, nil, Modifiers{}, 15},
+		{"dirt diamond shovel", dirt, diamondShovel, Modifiers{}, 2},
+		{"stone efficiency V", stone, diamondPickaxe, Modifiers{Efficiency: 5}, 2},
+		{"stone efficiency V haste II", stone, diamondPickaxe, Modifiers{Efficiency: 5, Haste: 2}, 0},
+		{"efficiency ignored by hand", stone, nil, Modifiers{Efficiency: 5}, 150},
+		{"stone mining fatigue I", stone, diamondPickaxe, Modifiers{MiningFatigue: 1}, 19},
+		{"stone underwater", stone, diamondPickaxe, Modifiers{Underwater: true}, 29},
+		{"stone underwater aqua affinity", stone, diamondPickaxe, Modifiers{Underwater: true, AquaAffinity: true}, 6},
+		{"stone airborne", stone, diamondPickaxe, Modifiers{Airborne: true}, 29},
+		{"stone underwater and airborne", stone, diamondPickaxe, Modifiers{Underwater: true, Airborne: true}, 141},
+		{"block_break_speed attribute", stone, diamondPickaxe, Modifiers{BlockBreakSpeed: 2}, 3},
+		{"mining_efficiency attribute", stone, diamondPickaxe, Modifiers{MiningEfficiency: 2}, 5},
+		{"instant", shortGras, nil, Modifiers{}, 0},
+	}
+	for _, c := range cases {
+		got, ok, err := BreakTicks(c.state, c.item, c.mods)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if !ok {
+			t.Errorf("%s: unexpectedly unbreakable", c.name)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("%s: expected %d ticks, got %d", c.name, c.want, got)
+		}
+	}
+}
+
+func TestBreakTicksUnbreakable(t *testing.T) {
+	if _, ok, err := BreakTicks(bedrock, diamondPickaxe, Modifiers{Efficiency: 5, Haste: 2}); ok || err != nil {
+		t.Fatalf("expected bedrock to be unbreakable")
+	}
+}
+
+// canHarvest is CanHarvest for states whose requires_tool is known.
+func canHarvest(t *testing.T, state loader.ShapeInfo, item *loader.ItemInfo) bool {
+	t.Helper()
+	ok, err := CanHarvest(state, item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func TestCanHarvest(t *testing.T) {
+	if canHarvest(t, stone, nil) {
+		t.Fatalf("stone should not drop by hand")
+	}
+	if !canHarvest(t, stone, woodenPickaxe) {
+		t.Fatalf("stone should drop with a wooden pickaxe")
+	}
+	if canHarvest(t, ironOre, goldenPickaxe) {
+		t.Fatalf("iron ore should not drop with a golden pickaxe")
+	}
+	if !canHarvest(t, obsidian, diamondPickaxe) || canHarvest(t, obsidian, ironPickaxe) {
+		t.Fatalf("obsidian needs a diamond pickaxe")
+	}
+	if !canHarvest(t, dirt, nil) {
+		t.Fatalf("dirt should drop by hand")
+	}
+}
+
+func TestRequiresToolUnknown(t *testing.T) {
+	// As loaded from data exported before requires_tool.
+	oldStone := stone
+	oldStone.RequiresTool = nil
+
+	if _, _, err := BreakTicks(oldStone, nil, Modifiers{}); !errors.Is(err, ErrRequiresToolUnknown) {
+		t.Fatalf("expected ErrRequiresToolUnknown by hand, got %v", err)
+	}
+	if _, err := CanHarvest(oldStone, nil); !errors.Is(err, ErrRequiresToolUnknown) {
+		t.Fatalf("expected ErrRequiresToolUnknown by hand, got %v", err)
+	}
+	// The correct tool breaks at full speed and drops either way.
+	if ticks, _, err := BreakTicks(oldStone, woodenPickaxe, Modifiers{}); err != nil || ticks != 23 {
+		t.Fatalf("expected 23 ticks with a wooden pickaxe, got %d (%v)", ticks, err)
+	}
+	if !canHarvest(t, oldStone, woodenPickaxe) {
+		t.Fatalf("stone should drop with a wooden pickaxe")
+	}
+	// So do unbreakable and instantly broken blocks.
+	if _, ok, err := BreakTicks(bedrock, nil, Modifiers{}); ok || err != nil {
+		t.Fatalf("expected bedrock to be unbreakable, got ok=%v err=%v", ok, err)
+	}
+	if ticks, ok, err := BreakTicks(shortGras, nil, Modifiers{}); !ok || ticks != 0 || err != nil {
+		t.Fatalf("expected short grass to break instantly, got %d ok=%v err=%v", ticks, ok, err)
+	}
+}
+
+func TestRequiresToolUnknownInShippedData(t *testing.T) {
+	path := filepath.Join("..", "..", "data", "1.21.6", "blocks", "minecraft", "stone.json")
+	if _, err := os.Stat(path); err != nil {
+		t.Skipf("shipped data not available: %v", err)
+	}
+	m, err := loader.LoadBlocksFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stone := m[loader.StateKey{BlockID: "minecraft:stone"}]
+	if _, _, err := BreakTicks(stone, nil, Modifiers{}); !errors.Is(err, ErrRequiresToolUnknown) {
+		t.Fatalf("expected ErrRequiresToolUnknown for shipped stone by hand, got %v", err)
+	}
+}
+
+func TestBreakTicksToolComponent(t *testing.T) {
+	yes, no := true, false
+	fifteen := 15.0
+	sword := &loader.ItemInfo{
+		ID: "minecraft:iron_sword",
+		Components: loader.ItemComponents{Tool: &loader.ToolComponent{
+			Rules: []loader.ToolRule{
+				{Blocks: []string{"minecraft:cobweb"}, Speed: &fifteen, CorrectForDrops: &yes},
+			},
+			DefaultMiningSpeed: 1,
+			DamagePerBlock:     2,
+		}},
+	}
+	cobweb := loader.ShapeInfo{BlockID: "minecraft:cobweb", Hardness: 4, Diggable: true, RequiresTool: &required}
+	if ticks, ok, _ := BreakTicks(cobweb, sword, Modifiers{}); !ok || ticks != 8 {
+		t.Fatalf("expected sword to cut cobweb in 8 ticks, got %d", ticks)
+	}
+	if ticks, _, _ := BreakTicks(cobweb, nil, Modifiers{}); ticks != 400 {
+		t.Fatalf("expected 400 ticks by hand, got %d", ticks)
+	}
+
+	// A pickaxe whose component denies drops from obsidian: full speed, no drops.
+	six := 6.0
+	pick := &loader.ItemInfo{
+		ID: "minecraft:iron_pickaxe",
+		Components: loader.ItemComponents{Tool: &loader.ToolComponent{
+			Rules: []loader.ToolRule{
+				{Tag: "minecraft:incorrect_for_iron_tool", CorrectForDrops: &no},
+				{Tag: "minecraft:mineable/pickaxe", Speed: &six, CorrectForDrops: &yes},
+			},
+			DefaultMiningSpeed: 1,
+		}},
+	}
+	if ticks, _, _ := BreakTicks(obsidian, pick, Modifiers{}); ticks != 834 {
+		t.Fatalf("expected 834 ticks, got %d", ticks)
+	}
+	if canHarvest(t, obsidian, pick) || !canHarvest(t, ironOre, pick) {
+		t.Fatalf("unexpected harvest result from tool component")
+	}
+}
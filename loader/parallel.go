@@ -0,0 +1,85 @@
+package loader
+
+import (
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// listJSONFiles returns every *.json file under root in fsys, in lexical
+// walk order.
+func listJSONFiles(fsys fs.FS, root string) ([]string, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !strings.HasSuffix(d.Name(), ".json") {
+			return nil
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// readFileFS reads a file from fsys, wrapping errors like os.ReadFile callers do.
+func readFileFS(fsys fs.FS, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// decodeAll runs decode over paths on a bounded pool of GOMAXPROCS workers.
+// Results keep the order of paths. If any file fails, the error for the
+// earliest failing path is returned.
+func decodeAll[T any](paths []string, decode func(string) (T, error)) ([]T, error) {
+	out, errs := decodeEach(paths, decode)
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to load file %s: %w", paths[i], err)
+		}
+	}
+	return out, nil
+}
+
+// decodeEach is decodeAll without the early error: it returns one result
+// and one error slot per path so callers can report every failure.
+func decodeEach[T any](paths []string, decode func(string) (T, error)) ([]T, []error) {
+	out := make([]T, len(paths))
+	errs := make([]error, len(paths))
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(paths) {
+		workers = len(paths)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				out[i], errs[i] = decode(paths[i])
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return out, errs
+}
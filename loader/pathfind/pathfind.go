@@ -0,0 +1,396 @@
+// Package pathfind searches for walkable routes through a block world with
+// A*. Nodes are the block positions an entity's feet occupy; moves model
+// walking, stepping up onto slabs and stairs, jumping one block, climbing
+// ladders, swimming and falling, using the ShapeInfo walkability helpers.
+package pathfind
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+// eps absorbs floating point noise when comparing heights.
+const eps = 1e-6
+
+var (
+	// ErrNoPath is returned when the goal cannot be reached.
+	ErrNoPath = errors.New("no path to goal")
+	// ErrNodeLimit is returned when the search expands Config.MaxNodes
+	// nodes without reaching the goal.
+	ErrNodeLimit = errors.New("node limit reached")
+)
+
+// Node is a block position holding an entity's feet.
+type Node struct {
+	X, Y, Z int
+}
+
+// MoveKind is how an entity gets from one node to the next.
+type MoveKind int
+
+const (
+	// MoveStart marks the first waypoint of a path.
+	MoveStart MoveKind = iota
+	MoveWalk
+	// MoveStep is a walk onto something no higher than a stair or the step
+	// height (slabs, stairs), which needs no jump.
+	MoveStep
+	// MoveJump is a climb of up to one block that needs a jump.
+	MoveJump
+	MoveFall
+	MoveClimb
+	MoveSwim
+)
+
+var moveNames = [...]string{"start", "walk", "step", "jump", "fall", "climb", "swim"}
+
+func (k MoveKind) String() string {
+	if k < 0 || int(k) >= len(moveNames) {
+		return "MoveKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return moveNames[k]
+}
+
+// Move is a single edge of the search graph.
+type Move struct {
+	From, To Node
+	Kind     MoveKind
+	// Rise is the change in standing height; negative when going down.
+	Rise float64
+}
+
+// CostFunc prices a move. Returning +Inf (or a negative value) forbids it.
+// The search heuristic is straight-line distance, so costs below the
+// distance a move covers can make paths suboptimal.
+type CostFunc func(m Move) float64
+
+// DefaultCost charges one per block walked, with surcharges for moves
+// that are slower or riskier in game.
+func DefaultCost(m Move) float64 {
+	switch m.Kind {
+	case MoveStep:
+		return 1.5
+	case MoveJump:
+		return 2
+	case MoveFall:
+		return 1 + float64(m.From.Y-m.To.Y)
+	case MoveClimb:
+		return 1.5
+	case MoveSwim:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Config describes the entity and bounds the search.
+type Config struct {
+	// Height is the entity's height in blocks (1.8 for players).
+	Height float64
+	// StepHeight is how high the entity can walk up without jumping.
+	StepHeight float64
+	// MaxDrop is the largest number of blocks the entity may fall.
+	MaxDrop int
+	// MaxNodes caps the number of nodes expanded; 0 means no limit.
+	MaxNodes int
+	// Cost prices moves; nil means DefaultCost.
+	Cost CostFunc
+}
+
+// DefaultConfig returns settings for a player.
+func DefaultConfig() Config {
+	return Config{
+		Height:     1.8,
+		StepHeight: 0.6,
+		MaxDrop:    3,
+		MaxNodes:   100000,
+		Cost:       DefaultCost,
+	}
+}
+
+// Waypoint is one step of a found path.
+type Waypoint struct {
+	Node
+	// Pos is where the entity stands: the centre of the block column at
+	// the height of whatever supports it.
+	Pos loader.Vec3
+	// Kind is the move that reached this waypoint.
+	Kind MoveKind
+	// Cost is the cumulative cost from the start.
+	Cost float64
+}
+
+// Find returns the cheapest path from start to goal, both included.
+func Find(world loader.BlockAccess, start, goal Node, cfg Config) ([]Waypoint, error) {
+	g := grid{world: world, cfg: cfg}
+	if g.cfg.Cost == nil {
+		g.cfg.Cost = DefaultCost
+	}
+	if !g.stand(start).valid() {
+		return nil, fmt.Errorf("start %v is not a standing position", start)
+	}
+	if !g.stand(goal).valid() {
+		return nil, fmt.Errorf("goal %v is not a standing position", goal)
+	}
+
+	best := map[Node]visit{start: {via: Move{To: start, Kind: MoveStart}}}
+	closed := make(map[Node]bool)
+	open := &nodeQueue{{node: start, priority: distance(start, goal)}}
+	expanded := 0
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(queued).node
+		if closed[cur] {
+			continue
+		}
+		if cur == goal {
+			return g.waypoints(goal, best), nil
+		}
+		closed[cur] = true
+		expanded++
+		if g.cfg.MaxNodes > 0 && expanded >= g.cfg.MaxNodes {
+			return nil, ErrNodeLimit
+		}
+
+		for _, m := range g.neighbors(cur) {
+			if closed[m.To] {
+				continue
+			}
+			c := g.cfg.Cost(m)
+			if c < 0 || math.IsInf(c, 1) || math.IsNaN(c) {
+				continue
+			}
+			total := best[cur].cost + c
+			if v, ok := best[m.To]; ok && v.cost <= total {
+				continue
+			}
+			best[m.To] = visit{cost: total, via: m}
+			heap.Push(open, queued{node: m.To, priority: total + distance(m.To, goal)})
+		}
+	}
+	return nil, ErrNoPath
+}
+
+// Neighbors lists the moves available from n, e.g. as an action space.
+func Neighbors(world loader.BlockAccess, n Node, cfg Config) []Move {
+	return grid{world: world, cfg: cfg}.neighbors(n)
+}
+
+// visit is the cheapest known way to reach a node.
+type visit struct {
+	cost float64
+	via  Move
+}
+
+// waypoints walks the via links back from goal and returns them in order.
+func (g grid) waypoints(goal Node, best map[Node]visit) []Waypoint {
+	var out []Waypoint
+	for n := goal; ; {
+		v := best[n]
+		out = append(out, Waypoint{
+			Node: n,
+			Pos:  loader.Vec3{X: float64(n.X) + 0.5, Y: g.stand(n).floor, Z: float64(n.Z) + 0.5},
+			Kind: v.via.Kind,
+			Cost: v.cost,
+		})
+		if v.via.Kind == MoveStart {
+			break
+		}
+		n = v.via.From
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return out
+}
+
+func distance(a, b Node) float64 {
+	dx := float64(a.X - b.X)
+	dy := float64(a.Y - b.Y)
+	dz := float64(a.Z - b.Z)
+	return math.Sqrt(dx*dx + dy*dy + dz*dz)
+}
+
+type grid struct {
+	world loader.BlockAccess
+	cfg   Config
+}
+
+// standing describes an entity with its feet in a given cell.
+type standing struct {
+	// floor is the height the entity stands at (the cell's own Y when
+	// nothing supports it).
+	floor float64
+	// solid is set when the feet cell is filled and cannot be entered.
+	solid bool
+	// clear is set when the entity's body fits.
+	clear bool
+
+	grounded bool
+	stair    bool
+	water    bool
+	climb    bool
+}
+
+func (s standing) valid() bool {
+	return !s.solid && s.clear && (s.grounded || s.water || s.climb)
+}
+
+// passable reports whether a body may occupy the block. Ladders count as
+// passable whatever their collision; lava never does.
+func passable(info loader.ShapeInfo) bool {
+	if info.Lava {
+		return false
+	}
+	return info.IsPassable() || info.IsClimbable()
+}
+
+func (g grid) stand(n Node) standing {
+	s := standing{floor: float64(n.Y)}
+	here := g.world.ShapeAt(n.X, n.Y, n.Z)
+	if !passable(here) {
+		// Partial blocks such as bottom slabs are stood on from inside
+		// their own cell.
+		top := here.IsStandingSurface()
+		if top <= 0 || top >= 1 {
+			s.solid = true
+			return s
+		}
+		s.floor += top
+		s.grounded = true
+		s.stair = here.IsStair()
+	} else {
+		below := g.world.ShapeAt(n.X, n.Y-1, n.Z)
+		if !passable(below) && below.IsStandingSurface() >= 1 {
+			s.floor = float64(n.Y-1) + below.IsStandingSurface()
+			s.grounded = true
+			s.stair = below.IsStair()
+		}
+		s.water = here.IsWater()
+		s.climb = here.IsClimbable()
+	}
+	s.clear = g.clearBetween(n.X, n.Z, s.floor, s.floor+g.cfg.Height)
+	return s
+}
+
+// clearBetween reports whether every cell of column x, z overlapping the
+// height range (bottom, top) is passable.
+func (g grid) clearBetween(x, z int, bottom, top float64) bool {
+	for y := int(math.Ceil(bottom - eps)); float64(y) < top-eps; y++ {
+		if !passable(g.world.ShapeAt(x, y, z)) {
+			return false
+		}
+	}
+	return true
+}
+
+var horizontalDirs = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+
+func (g grid) neighbors(n Node) []Move {
+	cur := g.stand(n)
+	if !cur.valid() {
+		return nil
+	}
+
+	var out []Move
+	for _, d := range horizontalDirs {
+		if m, ok := g.horizontal(n, cur, n.X+d[0], n.Z+d[1]); ok {
+			out = append(out, m)
+		}
+	}
+
+	if cur.climb || cur.water {
+		kind := MoveSwim
+		if cur.climb {
+			kind = MoveClimb
+		}
+		up := Node{X: n.X, Y: n.Y + 1, Z: n.Z}
+		if u := g.stand(up); u.valid() {
+			out = append(out, Move{From: n, To: up, Kind: kind, Rise: u.floor - cur.floor})
+		}
+	}
+	down := Node{X: n.X, Y: n.Y - 1, Z: n.Z}
+	if d := g.stand(down); d.valid() && (d.climb || d.water) {
+		kind := MoveSwim
+		if d.climb {
+			kind = MoveClimb
+		}
+		out = append(out, Move{From: n, To: down, Kind: kind, Rise: d.floor - cur.floor})
+	}
+	return out
+}
+
+// horizontal finds where moving one block towards column x, z lands: the
+// same level, one block up, or the first support below within MaxDrop.
+func (g grid) horizontal(from Node, cur standing, x, z int) (Move, bool) {
+	same := Node{X: x, Y: from.Y, Z: z}
+	s := g.stand(same)
+	switch {
+	case s.valid():
+		return g.level(from, cur, same, s)
+	case s.solid:
+		up := Node{X: x, Y: from.Y + 1, Z: z}
+		if u := g.stand(up); u.valid() {
+			return g.level(from, cur, up, u)
+		}
+	case s.clear:
+		for y := from.Y - 1; from.Y-y <= g.cfg.MaxDrop; y-- {
+			to := Node{X: x, Y: y, Z: z}
+			d := g.stand(to)
+			if d.valid() {
+				return Move{From: from, To: to, Kind: MoveFall, Rise: d.floor - cur.floor}, true
+			}
+			if d.solid || !d.clear {
+				break
+			}
+		}
+	}
+	return Move{}, false
+}
+
+// level classifies a move between two standing positions by how much the
+// entity has to rise.
+func (g grid) level(from Node, cur standing, to Node, s standing) (Move, bool) {
+	rise := s.floor - cur.floor
+	kind := MoveWalk
+	if rise > eps {
+		if rise > 1+eps {
+			return Move{}, false
+		}
+		// The entity is lifted in its own column before moving across.
+		if !g.clearBetween(from.X, from.Z, cur.floor, s.floor+g.cfg.Height) {
+			return Move{}, false
+		}
+		kind = MoveJump
+		if rise <= g.cfg.StepHeight+eps || s.stair {
+			kind = MoveStep
+		}
+	}
+	if kind == MoveWalk && s.water {
+		kind = MoveSwim
+	}
+	return Move{From: from, To: to, Kind: kind, Rise: rise}, true
+}
+
+type queued struct {
+	node     Node
+	priority float64
+}
+
+type nodeQueue []queued
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(queued)) }
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,260 @@
+package pathfind
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+type testWorld map[[3]int]loader.ShapeInfo
+
+func (w testWorld) ShapeAt(x, y, z int) loader.ShapeInfo {
+	if info, ok := w[[3]int{x, y, z}]; ok {
+		return info
+	}
+	return loader.ShapeInfo{Air: true}
+}
+
+// fill sets every block in the inclusive box to info.
+func (w testWorld) fill(x0, y0, z0, x1, y1, z1 int, info loader.ShapeInfo) {
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for z := z0; z <= z1; z++ {
+				w[[3]int{x, y, z}] = info
+			}
+		}
+	}
+}
+
+var (
+	fullBlock = loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 1, 1}}},
+		BlocksMovement: true,
+	}
+	bottomSlab = loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 0.5, 1}}},
+		BlocksMovement: true,
+		Slab:           true,
+	}
+	stairs = loader.ShapeInfo{
+		Collision: []loader.Box{
+			{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 0.5, 1}},
+			{Min: [3]float64{0.5, 0.5, 0}, Max: [3]float64{1, 1, 1}},
+		},
+		BlocksMovement: true,
+		Stair:          true,
+	}
+	ladder = loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0.8125, 0, 0}, Max: [3]float64{1, 1, 1}}},
+		BlocksMovement: true,
+		Climbable:      true,
+	}
+	water = loader.ShapeInfo{Fluid: true, Water: true}
+)
+
+// corridor returns a 3-wide stone floor along x whose walking level is y=0.
+func corridor(x0, x1 int) testWorld {
+	w := testWorld{}
+	w.fill(x0, -1, -1, x1, -1, 1, fullBlock)
+	return w
+}
+
+func kinds(path []Waypoint) []MoveKind {
+	out := make([]MoveKind, len(path))
+	for i, p := range path {
+		out[i] = p.Kind
+	}
+	return out
+}
+
+func hasKind(path []Waypoint, k MoveKind) bool {
+	for _, p := range path {
+		if p.Kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFindFlatWalk(t *testing.T) {
+	w := corridor(-1, 6)
+	path, err := Find(w, Node{0, 0, 0}, Node{4, 0, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if len(path) != 5 {
+		t.Fatalf("expected 5 waypoints, got %d: %v", len(path), kinds(path))
+	}
+	if path[0].Kind != MoveStart || path[0].Cost != 0 {
+		t.Fatalf("unexpected first waypoint %+v", path[0])
+	}
+	for _, p := range path[1:] {
+		if p.Kind != MoveWalk || p.Z != 0 || p.Pos.Y != 0 {
+			t.Fatalf("expected straight walk, got %+v", p)
+		}
+	}
+	if last := path[len(path)-1]; last.Cost != 4 || last.Pos != (loader.Vec3{X: 4.5, Y: 0, Z: 0.5}) {
+		t.Fatalf("unexpected last waypoint %+v", last)
+	}
+}
+
+func TestFindJumpAndFall(t *testing.T) {
+	w := corridor(-1, 6)
+	w.fill(2, 0, -1, 2, 0, 1, fullBlock)
+
+	path, err := Find(w, Node{0, 0, 0}, Node{4, 0, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	want := []MoveKind{MoveStart, MoveWalk, MoveJump, MoveFall, MoveWalk}
+	got := kinds(path)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+	if path[2].Node != (Node{2, 1, 0}) || path[2].Pos.Y != 1 {
+		t.Fatalf("expected to jump onto the block, got %+v", path[2])
+	}
+
+	// Forbidding jumps through the cost function leaves no route.
+	cfg := DefaultConfig()
+	cfg.Cost = func(m Move) float64 {
+		if m.Kind == MoveJump {
+			return math.Inf(1)
+		}
+		return DefaultCost(m)
+	}
+	if _, err := Find(w, Node{0, 0, 0}, Node{4, 0, 0}, cfg); !errors.Is(err, ErrNoPath) {
+		t.Fatalf("expected ErrNoPath without jumps, got %v", err)
+	}
+}
+
+func TestFindStepsOntoSlabsAndStairs(t *testing.T) {
+	w := corridor(-1, 6)
+	w.fill(2, 0, -1, 2, 0, 1, bottomSlab)
+
+	path, err := Find(w, Node{0, 0, 0}, Node{4, 0, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if hasKind(path, MoveJump) || !hasKind(path, MoveStep) {
+		t.Fatalf("expected a step without jumping, got %v", kinds(path))
+	}
+	if path[2].Node != (Node{2, 0, 0}) || path[2].Pos.Y != 0.5 {
+		t.Fatalf("expected to stand on the slab, got %+v", path[2])
+	}
+
+	w = corridor(-1, 6)
+	w.fill(2, 0, -1, 2, 0, 1, stairs)
+	path, err = Find(w, Node{0, 0, 0}, Node{2, 1, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if last := path[len(path)-1]; last.Kind != MoveStep || last.Pos.Y != 1 {
+		t.Fatalf("expected to step up the stairs, got %+v", last)
+	}
+}
+
+func TestFindTwoHighWall(t *testing.T) {
+	w := corridor(-1, 6)
+	w.fill(2, 0, -1, 2, 1, 1, fullBlock)
+	if _, err := Find(w, Node{0, 0, 0}, Node{4, 0, 0}, DefaultConfig()); !errors.Is(err, ErrNoPath) {
+		t.Fatalf("expected ErrNoPath, got %v", err)
+	}
+}
+
+func TestFindClimbsLadder(t *testing.T) {
+	w := corridor(-1, 6)
+	w.fill(2, 0, -1, 2, 1, 1, fullBlock)
+	w.fill(1, 0, 0, 1, 1, 0, ladder)
+
+	path, err := Find(w, Node{0, 0, 0}, Node{2, 2, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if !hasKind(path, MoveClimb) {
+		t.Fatalf("expected to climb, got %v", kinds(path))
+	}
+	if last := path[len(path)-1]; last.Pos.Y != 2 {
+		t.Fatalf("expected to end on top of the wall, got %+v", last)
+	}
+}
+
+func TestFindFallRespectsMaxDrop(t *testing.T) {
+	w := corridor(-1, 1)
+	w.fill(2, -3, -1, 5, -3, 1, fullBlock)
+
+	path, err := Find(w, Node{0, 0, 0}, Node{3, -2, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	if path[2].Kind != MoveFall || path[2].Node != (Node{2, -2, 0}) {
+		t.Fatalf("expected a two block fall, got %+v", path[2])
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxDrop = 1
+	if _, err := Find(w, Node{0, 0, 0}, Node{3, -2, 0}, cfg); !errors.Is(err, ErrNoPath) {
+		t.Fatalf("expected ErrNoPath with MaxDrop 1, got %v", err)
+	}
+}
+
+func TestFindSwims(t *testing.T) {
+	w := corridor(-1, 1)
+	w.fill(2, -2, -1, 3, -2, 1, fullBlock)
+	w.fill(2, -1, -1, 3, 0, 1, water)
+	w.fill(4, -1, -1, 6, -1, 1, fullBlock)
+
+	path, err := Find(w, Node{0, 0, 0}, Node{5, 0, 0}, DefaultConfig())
+	if err != nil {
+		t.Fatalf("Find error: %v", err)
+	}
+	want := []MoveKind{MoveStart, MoveWalk, MoveSwim, MoveSwim, MoveWalk, MoveWalk}
+	got := kinds(path)
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	w := corridor(-1, 6)
+	if _, err := Find(w, Node{0, 5, 0}, Node{4, 0, 0}, DefaultConfig()); err == nil {
+		t.Fatalf("expected error for a start in mid-air")
+	}
+	if _, err := Find(w, Node{0, 0, 0}, Node{4, -1, 0}, DefaultConfig()); err == nil {
+		t.Fatalf("expected error for a goal inside a block")
+	}
+
+	cfg := DefaultConfig()
+	cfg.MaxNodes = 2
+	if _, err := Find(w, Node{0, 0, 0}, Node{6, 0, 0}, cfg); !errors.Is(err, ErrNodeLimit) {
+		t.Fatalf("expected ErrNodeLimit, got %v", err)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	w := corridor(-1, 1)
+	moves := Neighbors(w, Node{0, 0, 0}, DefaultConfig())
+	if len(moves) != 4 {
+		t.Fatalf("expected 4 walk moves from the centre, got %+v", moves)
+	}
+	for _, m := range moves {
+		if m.Kind != MoveWalk {
+			t.Fatalf("expected walk, got %+v", m)
+		}
+	}
+	if moves := Neighbors(w, Node{0, 3, 0}, DefaultConfig()); moves != nil {
+		t.Fatalf("expected no moves from mid-air, got %+v", moves)
+	}
+}
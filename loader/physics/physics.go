@@ -0,0 +1,232 @@
+// Package physics resolves entity movement against block collision shapes
+// the same way Minecraft's Entity.move does: the Y axis is clamped first,
+// then the larger horizontal axis, then the other, with an optional
+// step-up pass when a grounded entity hits something horizontally.
+package physics
+
+import (
+	"math"
+	"sort"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+// epsilon matches the tolerance Minecraft uses when comparing shape edges.
+const epsilon = 1e-7
+
+// MoveResult is the outcome of resolving one tick of movement.
+type MoveResult struct {
+	// Movement is the clamped displacement to apply to the entity.
+	Movement loader.Vec3
+
+	OnGround            bool
+	HorizontalCollision bool
+	VerticalCollision   bool
+}
+
+// EntityBox returns the bounding box of an entity with the given dimensions
+// whose feet are centred on pos.
+func EntityBox(pos loader.Vec3, dims loader.EntityDimensions) loader.AABB {
+	hw := dims.Width / 2
+	return loader.AABB{
+		Min: loader.Vec3{X: pos.X - hw, Y: pos.Y, Z: pos.Z - hw},
+		Max: loader.Vec3{X: pos.X + hw, Y: pos.Y + dims.Height, Z: pos.Z + hw},
+	}
+}
+
+// Move resolves movement of box through world. stepHeight is the entity's
+// step height (0.6 for players and most mobs) and onGround whether it was
+// standing on something before this move.
+func Move(world loader.BlockAccess, box loader.AABB, movement loader.Vec3, stepHeight float64, onGround bool) MoveResult {
+	collisions := CollisionBoxes(world, box.Stretch(movement.X, movement.Y, movement.Z))
+	adjusted := adjustForCollisions(movement, box, collisions)
+
+	collidedX := !approxEqual(movement.X, adjusted.X)
+	collidedY := movement.Y != adjusted.Y
+	collidedZ := !approxEqual(movement.Z, adjusted.Z)
+	landed := collidedY && movement.Y < 0
+
+	if stepHeight > 0 && (onGround || landed) && (collidedX || collidedZ) {
+		if stepped, ok := tryStep(world, box, movement, adjusted, landed, stepHeight); ok {
+			adjusted = stepped
+			collidedX = !approxEqual(movement.X, adjusted.X)
+			collidedY = movement.Y != adjusted.Y
+			collidedZ = !approxEqual(movement.Z, adjusted.Z)
+		}
+	}
+
+	return MoveResult{
+		Movement:            adjusted,
+		OnGround:            collidedY && movement.Y < 0,
+		HorizontalCollision: collidedX || collidedZ,
+		VerticalCollision:   collidedY,
+	}
+}
+
+// CollisionBoxes returns every block collision box in world that intersects
+// region. Neighbouring blocks are included so shapes taller than a full
+// block (fences, walls) are found.
+func CollisionBoxes(world loader.BlockAccess, region loader.AABB) []loader.AABB {
+	x0 := int(math.Floor(region.Min.X-epsilon)) - 1
+	y0 := int(math.Floor(region.Min.Y-epsilon)) - 1
+	z0 := int(math.Floor(region.Min.Z-epsilon)) - 1
+	x1 := int(math.Floor(region.Max.X+epsilon)) + 1
+	y1 := int(math.Floor(region.Max.Y+epsilon)) + 1
+	z1 := int(math.Floor(region.Max.Z+epsilon)) + 1
+
+	var out []loader.AABB
+	for x := x0; x <= x1; x++ {
+		for y := y0; y <= y1; y++ {
+			for z := z0; z <= z1; z++ {
+				for _, b := range world.ShapeAt(x, y, z).WorldCollisionBoxesAt(x, y, z) {
+					if b.Intersects(region) {
+						out = append(out, b)
+					}
+				}
+			}
+		}
+	}
+	return out
+}
+
+// tryStep re-runs the move lifted by each candidate step height and keeps
+// the first one that gets further horizontally. landed is set when this
+// move's fall was stopped by the ground; steps are then measured from
+// where it landed. A jump clamped by a ceiling steps from the feet.
+func tryStep(world loader.BlockAccess, box loader.AABB, movement, adjusted loader.Vec3, landed bool, stepHeight float64) (loader.Vec3, bool) {
+	base := box
+	if landed {
+		base = box.Offset(0, adjusted.Y, 0)
+	}
+	region := base.Stretch(movement.X, stepHeight, movement.Z)
+	if !landed {
+		region = region.Stretch(0, -1e-5, 0)
+	}
+	collisions := CollisionBoxes(world, region)
+
+	for _, h := range stepHeights(base, collisions, stepHeight, adjusted.Y) {
+		try := adjustForCollisions(loader.Vec3{X: movement.X, Y: h, Z: movement.Z}, base, collisions)
+		if horizontalLenSq(try) > horizontalLenSq(adjusted) {
+			try.Y -= box.Min.Y - base.Min.Y
+			return try, true
+		}
+	}
+	return loader.Vec3{}, false
+}
+
+// stepHeights lists the distinct heights (relative to the entity's feet) of
+// collision box edges within reach, ascending.
+func stepHeights(box loader.AABB, collisions []loader.AABB, stepHeight, skip float64) []float64 {
+	seen := make(map[float64]bool)
+	var out []float64
+	for _, c := range collisions {
+		for _, y := range [2]float64{c.Min.Y, c.Max.Y} {
+			h := y - box.Min.Y
+			if h < 0 || h == skip || h > stepHeight || seen[h] {
+				continue
+			}
+			seen[h] = true
+			out = append(out, h)
+		}
+	}
+	sort.Float64s(out)
+	return out
+}
+
+// adjustForCollisions clamps movement axis by axis: Y, then whichever
+// horizontal axis has the larger magnitude, then the other.
+func adjustForCollisions(movement loader.Vec3, box loader.AABB, collisions []loader.AABB) loader.Vec3 {
+	if len(collisions) == 0 {
+		return movement
+	}
+	x, y, z := movement.X, movement.Y, movement.Z
+
+	if y != 0 {
+		y = maxOffset(axisY, box, collisions, y)
+		if y != 0 {
+			box = box.Offset(0, y, 0)
+		}
+	}
+
+	zFirst := math.Abs(x) < math.Abs(z)
+	if zFirst && z != 0 {
+		z = maxOffset(axisZ, box, collisions, z)
+		if z != 0 {
+			box = box.Offset(0, 0, z)
+		}
+	}
+	if x != 0 {
+		x = maxOffset(axisX, box, collisions, x)
+		if !zFirst && x != 0 {
+			box = box.Offset(x, 0, 0)
+		}
+	}
+	if !zFirst && z != 0 {
+		z = maxOffset(axisZ, box, collisions, z)
+	}
+	return loader.Vec3{X: x, Y: y, Z: z}
+}
+
+type axis int
+
+const (
+	axisX axis = iota
+	axisY
+	axisZ
+)
+
+func component(v loader.Vec3, a axis) float64 {
+	switch a {
+	case axisX:
+		return v.X
+	case axisY:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
+
+// maxOffset returns how far box can move along a (up to offset) before it
+// hits one of the collision boxes.
+func maxOffset(a axis, box loader.AABB, collisions []loader.AABB, offset float64) float64 {
+	if math.Abs(offset) < epsilon {
+		return 0
+	}
+	for _, c := range collisions {
+		if !overlapsOtherAxes(a, box, c) {
+			continue
+		}
+		if offset > 0 && component(c.Min, a) >= component(box.Max, a)-epsilon {
+			offset = math.Min(offset, component(c.Min, a)-component(box.Max, a))
+		} else if offset < 0 && component(c.Max, a) <= component(box.Min, a)+epsilon {
+			offset = math.Max(offset, component(c.Max, a)-component(box.Min, a))
+		}
+	}
+	if math.Abs(offset) < epsilon {
+		return 0
+	}
+	return offset
+}
+
+// overlapsOtherAxes reports whether box and c overlap on both axes other
+// than a; only then can c block movement along a.
+func overlapsOtherAxes(a axis, box, c loader.AABB) bool {
+	for _, o := range [3]axis{axisX, axisY, axisZ} {
+		if o == a {
+			continue
+		}
+		if component(c.Max, o) <= component(box.Min, o)+epsilon ||
+			component(c.Min, o) >= component(box.Max, o)-epsilon {
+			return false
+		}
+	}
+	return true
+}
+
+func horizontalLenSq(v loader.Vec3) float64 {
+	return v.X*v.X + v.Z*v.Z
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(b-a) < 1e-5
+}
@@ -0,0 +1,182 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+type testWorld map[[3]int]loader.ShapeInfo
+
+func (w testWorld) ShapeAt(x, y, z int) loader.ShapeInfo {
+	if info, ok := w[[3]int{x, y, z}]; ok {
+		return info
+	}
+	return loader.ShapeInfo{Air: true}
+}
+
+var (
+	fullBlock = loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 1, 1}}},
+		BlocksMovement: true,
+	}
+	bottomSlab = loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 0.5, 1}}},
+		BlocksMovement: true,
+		Slab:           true,
+	}
+	playerDims = loader.EntityDimensions{Width: 0.6, Height: 1.8, EyeHeight: 1.62}
+)
+
+// floorWorld returns a 5x5 stone floor whose top face is at y=0.
+func floorWorld() testWorld {
+	w := testWorld{}
+	for x := -2; x <= 2; x++ {
+		for z := -2; z <= 2; z++ {
+			w[[3]int{x, -1, z}] = fullBlock
+		}
+	}
+	return w
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMoveLandsOnFloor(t *testing.T) {
+	w := floorWorld()
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0.3, Z: 0.5}, playerDims)
+
+	res := Move(w, box, loader.Vec3{Y: -0.5}, 0.6, false)
+	if !near(res.Movement.Y, -0.3) {
+		t.Fatalf("expected to fall 0.3, got %f", res.Movement.Y)
+	}
+	if !res.OnGround || !res.VerticalCollision {
+		t.Fatalf("expected OnGround and VerticalCollision, got %+v", res)
+	}
+	if res.HorizontalCollision {
+		t.Fatalf("unexpected horizontal collision")
+	}
+}
+
+func TestMoveFreeFall(t *testing.T) {
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 10, Z: 0.5}, playerDims)
+	res := Move(testWorld{}, box, loader.Vec3{X: 0.1, Y: -0.5, Z: 0.2}, 0.6, false)
+	if res.Movement != (loader.Vec3{X: 0.1, Y: -0.5, Z: 0.2}) {
+		t.Fatalf("expected unobstructed movement, got %+v", res.Movement)
+	}
+	if res.OnGround || res.HorizontalCollision || res.VerticalCollision {
+		t.Fatalf("unexpected collision flags: %+v", res)
+	}
+}
+
+func TestMoveWallStopsHorizontal(t *testing.T) {
+	w := floorWorld()
+	w[[3]int{1, 0, 0}] = fullBlock
+	w[[3]int{1, 1, 0}] = fullBlock
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0, Z: 0.5}, playerDims)
+
+	res := Move(w, box, loader.Vec3{X: 0.5, Y: -0.08}, 0.6, true)
+	if !near(res.Movement.X, 0.2) {
+		t.Fatalf("expected x clamped to 0.2, got %f", res.Movement.X)
+	}
+	if !res.HorizontalCollision || !res.OnGround {
+		t.Fatalf("expected horizontal collision while grounded, got %+v", res)
+	}
+	if !near(res.Movement.Y, 0) {
+		t.Fatalf("expected no vertical movement, got %f", res.Movement.Y)
+	}
+}
+
+func TestMoveStepsOntoSlab(t *testing.T) {
+	w := floorWorld()
+	w[[3]int{1, 0, 0}] = bottomSlab
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0, Z: 0.5}, playerDims)
+
+	res := Move(w, box, loader.Vec3{X: 0.3, Y: -0.08}, 0.6, true)
+	if !near(res.Movement.X, 0.3) || !near(res.Movement.Y, 0.5) {
+		t.Fatalf("expected to step up 0.5 and keep moving, got %+v", res.Movement)
+	}
+	if res.HorizontalCollision {
+		t.Fatalf("expected step to clear the horizontal collision")
+	}
+
+	// Without step height the slab is a wall.
+	res = Move(w, box, loader.Vec3{X: 0.3, Y: -0.08}, 0, true)
+	if !near(res.Movement.X, 0.2) || !res.HorizontalCollision {
+		t.Fatalf("expected to be blocked without step height, got %+v", res)
+	}
+}
+
+func TestMoveJumpIntoCeilingStepsFromFeet(t *testing.T) {
+	// A crouching player under a top trapdoor jumps towards a top slab whose
+	// underside is level with their head. The trapdoor clamps the jump, so
+	// the step pass starts from the feet and slides under the slab.
+	topTrapdoor := loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0, 0.8125, 0}, Max: [3]float64{1, 1, 1}}},
+		BlocksMovement: true,
+	}
+	topSlab := loader.ShapeInfo{
+		Collision:      []loader.Box{{Min: [3]float64{0, 0.5, 0}, Max: [3]float64{1, 1, 1}}},
+		BlocksMovement: true,
+		Slab:           true,
+	}
+	w := floorWorld()
+	w[[3]int{0, 1, 0}] = topTrapdoor
+	w[[3]int{1, 1, 0}] = topSlab
+	crouching := loader.EntityDimensions{Width: 0.6, Height: 1.5, EyeHeight: 1.27}
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0, Z: 0.5}, crouching)
+
+	res := Move(w, box, loader.Vec3{X: 0.4, Y: 0.42}, 0.6, true)
+	if !near(res.Movement.X, 0.4) || !near(res.Movement.Y, 0) {
+		t.Fatalf("expected to slide under the slab at floor level, got %+v", res.Movement)
+	}
+}
+
+func TestMoveDoesNotStepFullBlock(t *testing.T) {
+	w := floorWorld()
+	w[[3]int{1, 0, 0}] = fullBlock
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0, Z: 0.5}, playerDims)
+
+	res := Move(w, box, loader.Vec3{X: 0.3, Y: -0.08}, 0.6, true)
+	if !near(res.Movement.X, 0.2) || !near(res.Movement.Y, 0) {
+		t.Fatalf("expected to stop at the block, got %+v", res.Movement)
+	}
+}
+
+func TestMoveLargerHorizontalAxisFirst(t *testing.T) {
+	// A pillar diagonal to the entity: moving mostly along z, the z pass runs
+	// first and is free, so the x pass then hits the pillar.
+	w := testWorld{[3]int{1, 0, 1}: fullBlock}
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0, Z: 0.5}, playerDims)
+
+	res := Move(w, box, loader.Vec3{X: 0.4, Z: 0.8}, 0, false)
+	if !near(res.Movement.Z, 0.8) || !near(res.Movement.X, 0.2) {
+		t.Fatalf("unexpected movement %+v", res.Movement)
+	}
+}
+
+func TestMoveOverWorld(t *testing.T) {
+	table, err := loader.NewStateTable(map[loader.StateKey]loader.ShapeInfo{
+		{BlockID: "minecraft:air"}:   {StateID: 0, Air: true},
+		{BlockID: "minecraft:stone"}: {StateID: 1, Collision: fullBlock.Collision, BlocksMovement: true},
+	})
+	if err != nil {
+		t.Fatalf("NewStateTable error: %v", err)
+	}
+	w := loader.NewWorld(table)
+	for x := -2; x <= 2; x++ {
+		for z := -2; z <= 2; z++ {
+			if err := w.SetStateID(x, -1, z, 1); err != nil {
+				t.Fatalf("SetStateID error: %v", err)
+			}
+		}
+	}
+
+	box := EntityBox(loader.Vec3{X: 0.5, Y: 0.3, Z: 0.5}, playerDims)
+	res := Move(w, box, loader.Vec3{Y: -0.5}, 0.6, false)
+	if !near(res.Movement.Y, -0.3) || !res.OnGround {
+		t.Fatalf("expected to land on the world floor, got %+v", res)
+	}
+}
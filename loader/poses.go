@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PoseTable maps entity pose ordinals (the varint sent in the Pose entity
+// metadata field) to pose names and back. Names are lower case, matching
+// the keys of EntityInfo.PoseDimensions.
+type PoseTable struct {
+	names    []string
+	ordinals map[string]int
+}
+
+// NewPoseTable builds a PoseTable from an ordinal -> name map.
+func NewPoseTable(poses map[int]string) (*PoseTable, error) {
+	maxOrd := -1
+	for ord := range poses {
+		if ord < 0 {
+			return nil, fmt.Errorf("negative pose ordinal %d", ord)
+		}
+		if ord > maxOrd {
+			maxOrd = ord
+		}
+	}
+	t := &PoseTable{
+		names:    make([]string, maxOrd+1),
+		ordinals: make(map[string]int, len(poses)),
+	}
+	for ord, name := range poses {
+		name = strings.ToLower(name)
+		if prev, dup := t.ordinals[name]; dup {
+			return nil, fmt.Errorf("pose %q has ordinals %d and %d", name, prev, ord)
+		}
+		t.names[ord] = name
+		t.ordinals[name] = ord
+	}
+	return t, nil
+}
+
+// LoadPoses reads a poses.json file. The file maps ordinals (as string keys)
+// to names; the key is trusted rather than the position in the file.
+func LoadPoses(path string) (*PoseTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return decodePoses(path, data)
+}
+
+// LoadPosesFS is LoadPoses for a file inside an fs.FS.
+func LoadPosesFS(fsys fs.FS, name string) (*PoseTable, error) {
+	data, err := readFileFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return decodePoses(name, data)
+}
+
+func decodePoses(name string, data []byte) (*PoseTable, error) {
+	var raw map[string]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", name, err)
+	}
+	poses := make(map[int]string, len(raw))
+	for k, v := range raw {
+		ord, err := strconv.Atoi(k)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid pose ordinal %q", name, k)
+		}
+		poses[ord] = v
+	}
+	t, err := NewPoseTable(poses)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return t, nil
+}
+
+// Len returns the number of ordinal slots (highest ordinal + 1).
+func (t *PoseTable) Len() int {
+	return len(t.names)
+}
+
+// ByOrdinal returns the pose name for an ordinal.
+func (t *PoseTable) ByOrdinal(ord int) (string, bool) {
+	if ord < 0 || ord >= len(t.names) || t.names[ord] == "" {
+		return "", false
+	}
+	return t.names[ord], true
+}
+
+// Ordinal returns the ordinal of a pose name. Matching is case-insensitive.
+func (t *PoseTable) Ordinal(name string) (int, bool) {
+	ord, ok := t.ordinals[strings.ToLower(name)]
+	return ord, ok
+}
+
+// PoseDimensionsFor returns the entity's dimensions in the named pose. The
+// exporter only records poses that differ from the default, so a pose with
+// no entry resolves to DefaultDimensions.
+func (e EntityInfo) PoseDimensionsFor(pose string) EntityDimensions {
+	if dims, ok := e.PoseDimensions[pose]; ok {
+		return dims
+	}
+	for name, dims := range e.PoseDimensions {
+		if strings.EqualFold(name, pose) {
+			return dims
+		}
+	}
+	return e.DefaultDimensions
+}
+
+// DimensionsForPoseOrdinal resolves the entity's dimensions from the pose
+// metadata varint a server sends.
+func (e EntityInfo) DimensionsForPoseOrdinal(poses *PoseTable, ord int) (EntityDimensions, error) {
+	name, ok := poses.ByOrdinal(ord)
+	if !ok {
+		return EntityDimensions{}, fmt.Errorf("unknown pose ordinal %d", ord)
+	}
+	return e.PoseDimensionsFor(name), nil
+}
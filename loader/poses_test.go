@@ -0,0 +1,59 @@
+package loader
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadPoses(t *testing.T) {
+	poses, err := LoadPoses(filepath.Join("testdata", "poses.json"))
+	if err != nil {
+		t.Fatalf("LoadPoses error: %v", err)
+	}
+	if poses.Len() != 18 {
+		t.Fatalf("expected 18 poses, got %d", poses.Len())
+	}
+	if name, ok := poses.ByOrdinal(2); !ok || name != "sleeping" {
+		t.Fatalf("expected ordinal 2 to be sleeping, got %q (ok=%v)", name, ok)
+	}
+	if ord, ok := poses.Ordinal("CROUCHING"); !ok || ord != 5 {
+		t.Fatalf("expected crouching ordinal 5, got %d (ok=%v)", ord, ok)
+	}
+	if _, ok := poses.ByOrdinal(99); ok {
+		t.Fatalf("expected ordinal 99 to be unknown")
+	}
+}
+
+func TestDimensionsForPoseOrdinal(t *testing.T) {
+	poses, err := LoadPoses(filepath.Join("testdata", "poses.json"))
+	if err != nil {
+		t.Fatalf("LoadPoses error: %v", err)
+	}
+	zombie, err := LoadEntityFile(filepath.Join("testdata", "entities", "minecraft", "zombie.json"))
+	if err != nil {
+		t.Fatalf("LoadEntityFile error: %v", err)
+	}
+
+	sleeping, _ := poses.Ordinal("sleeping")
+	dims, err := zombie.DimensionsForPoseOrdinal(poses, sleeping)
+	if err != nil {
+		t.Fatalf("DimensionsForPoseOrdinal error: %v", err)
+	}
+	if dims.Height != zombie.PoseDimensions["SLEEPING"].Height {
+		t.Fatalf("expected sleeping height %f, got %f", zombie.PoseDimensions["SLEEPING"].Height, dims.Height)
+	}
+
+	// Poses without an override fall back to the default dimensions.
+	digging, _ := poses.Ordinal("digging")
+	dims, err = zombie.DimensionsForPoseOrdinal(poses, digging)
+	if err != nil {
+		t.Fatalf("DimensionsForPoseOrdinal error: %v", err)
+	}
+	if dims != zombie.DefaultDimensions {
+		t.Fatalf("expected default dimensions, got %+v", dims)
+	}
+
+	if _, err := zombie.DimensionsForPoseOrdinal(poses, 99); err == nil {
+		t.Fatalf("expected error for unknown ordinal")
+	}
+}
@@ -0,0 +1,364 @@
+// Package raycast traces line segments through a block world, the way
+// Minecraft's BlockView.raycast does: it walks the block grid cell by cell
+// (a DDA traversal) and intersects each cell's outline or collision boxes,
+// optionally stopping at fluids too.
+package raycast
+
+import (
+	"math"
+	"strconv"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+// ShapeMode selects which block shape a ray is tested against, like
+// RaycastContext.ShapeType.
+type ShapeMode int
+
+const (
+	// ShapeOutline uses the outline shape: what the player targets and
+	// breaks (block selection).
+	ShapeOutline ShapeMode = iota
+	// ShapeCollider uses the collision shape: what entities bump into
+	// (line of sight for projectiles and mob vision).
+	ShapeCollider
+)
+
+// FluidMode selects which fluids stop a ray, like
+// RaycastContext.FluidHandling.
+type FluidMode int
+
+const (
+	// FluidNone ignores fluids entirely.
+	FluidNone FluidMode = iota
+	// FluidSourceOnly stops at source blocks (level 0) only, as when
+	// targeting with a bucket.
+	FluidSourceOnly
+	// FluidAny stops at any fluid.
+	FluidAny
+	// FluidWater stops at water but passes through lava.
+	FluidWater
+)
+
+// Options configures a raycast.
+type Options struct {
+	Shape  ShapeMode
+	Fluids FluidMode
+}
+
+// Face is the side of a block a ray entered through.
+type Face int
+
+const (
+	FaceDown Face = iota
+	FaceUp
+	FaceNorth
+	FaceSouth
+	FaceWest
+	FaceEast
+)
+
+var faceNames = [...]string{"down", "up", "north", "south", "west", "east"}
+
+// String returns the face name as the game spells it.
+func (f Face) String() string {
+	if f < 0 || int(f) >= len(faceNames) {
+		return "Face(" + strconv.Itoa(int(f)) + ")"
+	}
+	return faceNames[f]
+}
+
+// Normal returns the unit offset pointing out of the face, i.e. towards
+// the block a player would place against it.
+func (f Face) Normal() (dx, dy, dz int) {
+	switch f {
+	case FaceDown:
+		return 0, -1, 0
+	case FaceUp:
+		return 0, 1, 0
+	case FaceNorth:
+		return 0, 0, -1
+	case FaceSouth:
+		return 0, 0, 1
+	case FaceWest:
+		return -1, 0, 0
+	default:
+		return 1, 0, 0
+	}
+}
+
+// Hit describes where a ray struck a block.
+type Hit struct {
+	// X, Y, Z is the position of the block that was hit.
+	X, Y, Z int
+	// Face is the side of the block the ray entered through.
+	Face Face
+	// Point is the exact world-space intersection point.
+	Point loader.Vec3
+	// Distance is the distance from the ray origin to Point.
+	Distance float64
+
+	State loader.StateKey
+	Shape loader.ShapeInfo
+
+	// Fluid is set when the ray stopped at a fluid surface rather than the
+	// block's own shape.
+	Fluid bool
+	// Inside is set when the ray started inside the shape it hit; Point is
+	// then the origin and Face points back against the ray.
+	Inside bool
+}
+
+// Raycast traces the segment from -> to through world and returns the
+// first block hit, if any.
+func Raycast(world loader.StateAccess, from, to loader.Vec3, opts Options) (Hit, bool) {
+	d := loader.Vec3{X: to.X - from.X, Y: to.Y - from.Y, Z: to.Z - from.Z}
+
+	x := int(math.Floor(from.X))
+	y := int(math.Floor(from.Y))
+	z := int(math.Floor(from.Z))
+	stepX, tMaxX, tDeltaX := dda(from.X, d.X)
+	stepY, tMaxY, tDeltaY := dda(from.Y, d.Y)
+	stepZ, tMaxZ, tDeltaZ := dda(from.Z, d.Z)
+
+	for {
+		if hit, ok := testBlock(world, x, y, z, from, d, opts); ok {
+			hit.Distance = math.Sqrt(lenSq(sub(hit.Point, from)))
+			return hit, true
+		}
+
+		switch {
+		case tMaxX <= tMaxY && tMaxX <= tMaxZ:
+			if tMaxX > 1 {
+				return Hit{}, false
+			}
+			x += stepX
+			tMaxX += tDeltaX
+		case tMaxY <= tMaxZ:
+			if tMaxY > 1 {
+				return Hit{}, false
+			}
+			y += stepY
+			tMaxY += tDeltaY
+		default:
+			if tMaxZ > 1 {
+				return Hit{}, false
+			}
+			z += stepZ
+			tMaxZ += tDeltaZ
+		}
+	}
+}
+
+// dda returns the grid step along one axis, the segment parameter at which
+// the first cell boundary is crossed and the parameter span of one cell.
+func dda(origin, delta float64) (step int, tMax, tDelta float64) {
+	switch {
+	case delta > 0:
+		return 1, (math.Floor(origin) + 1 - origin) / delta, 1 / delta
+	case delta < 0:
+		return -1, (origin - math.Floor(origin)) / -delta, 1 / -delta
+	default:
+		return 0, math.Inf(1), math.Inf(1)
+	}
+}
+
+// testBlock intersects the segment with the shape (and, if enabled, the
+// fluid) of the block at x, y, z and returns the nearer hit.
+func testBlock(world loader.StateAccess, x, y, z int, from, d loader.Vec3, opts Options) (Hit, bool) {
+	info := world.ShapeAt(x, y, z)
+	if info.Air && !info.Fluid {
+		return Hit{}, false
+	}
+
+	boxes := info.Outline
+	if opts.Shape == ShapeCollider {
+		boxes = info.Collision
+	}
+	best, found := intersectBoxes(boxes, x, y, z, from, d)
+
+	if wantFluid(world, info, x, y, z, opts.Fluids) {
+		h := fluidHeight(world, info, x, y, z)
+		fluidBox := []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, h, 1}}}
+		if fh, ok := intersectBoxes(fluidBox, x, y, z, from, d); ok && (!found || fh.t < best.t) {
+			fh.fluid = true
+			best, found = fh, true
+		}
+	}
+	if !found {
+		return Hit{}, false
+	}
+
+	return Hit{
+		X: x, Y: y, Z: z,
+		Face:   best.face,
+		Point:  add(from, scale(d, best.t)),
+		State:  world.StateKeyAt(x, y, z),
+		Shape:  info,
+		Fluid:  best.fluid,
+		Inside: best.inside,
+	}, true
+}
+
+type boxHit struct {
+	t      float64
+	face   Face
+	fluid  bool
+	inside bool
+}
+
+// intersectBoxes returns the nearest intersection of the segment with any
+// of the block-local boxes placed at x, y, z.
+func intersectBoxes(boxes []loader.Box, x, y, z int, from, d loader.Vec3) (boxHit, bool) {
+	var best boxHit
+	found := false
+	for _, b := range boxes {
+		min := loader.Vec3{X: float64(x) + b.Min[0], Y: float64(y) + b.Min[1], Z: float64(z) + b.Min[2]}
+		max := loader.Vec3{X: float64(x) + b.Max[0], Y: float64(y) + b.Max[1], Z: float64(z) + b.Max[2]}
+		if h, ok := intersectBox(min, max, from, d); ok && (!found || h.t < best.t) {
+			best, found = h, true
+		}
+	}
+	return best, found
+}
+
+// intersectBox is the slab test for a segment from + t*d, t in [0, 1].
+func intersectBox(min, max, from, d loader.Vec3) (boxHit, bool) {
+	tEnter, tExit := math.Inf(-1), math.Inf(1)
+	enterAxis := -1
+	origin := [3]float64{from.X, from.Y, from.Z}
+	dir := [3]float64{d.X, d.Y, d.Z}
+	lo := [3]float64{min.X, min.Y, min.Z}
+	hi := [3]float64{max.X, max.Y, max.Z}
+
+	for a := 0; a < 3; a++ {
+		if dir[a] == 0 {
+			if origin[a] < lo[a] || origin[a] > hi[a] {
+				return boxHit{}, false
+			}
+			continue
+		}
+		t0 := (lo[a] - origin[a]) / dir[a]
+		t1 := (hi[a] - origin[a]) / dir[a]
+		if t0 > t1 {
+			t0, t1 = t1, t0
+		}
+		if t0 > tEnter {
+			tEnter, enterAxis = t0, a
+		}
+		if t1 < tExit {
+			tExit = t1
+		}
+	}
+	if tEnter > tExit || tExit < 0 || tEnter > 1 {
+		return boxHit{}, false
+	}
+
+	if tEnter < 0 || enterAxis < 0 {
+		// The origin is inside (or, for a zero-length ray, on) the box.
+		return boxHit{t: 0, face: facing(dir).opposite(), inside: true}, true
+	}
+	return boxHit{t: tEnter, face: entryFace(enterAxis, dir[enterAxis])}, true
+}
+
+// entryFace is the face crossed when entering a box along axis a while
+// moving in direction sign.
+func entryFace(a int, sign float64) Face {
+	switch a {
+	case 0:
+		if sign > 0 {
+			return FaceWest
+		}
+		return FaceEast
+	case 1:
+		if sign > 0 {
+			return FaceDown
+		}
+		return FaceUp
+	default:
+		if sign > 0 {
+			return FaceNorth
+		}
+		return FaceSouth
+	}
+}
+
+// facing returns the face whose normal is closest to dir, like
+// Direction.getFacing.
+func facing(dir [3]float64) Face {
+	best, bestDot := FaceNorth, math.Inf(-1)
+	for f := FaceDown; f <= FaceEast; f++ {
+		dx, dy, dz := f.Normal()
+		dot := dir[0]*float64(dx) + dir[1]*float64(dy) + dir[2]*float64(dz)
+		if dot > bestDot {
+			best, bestDot = f, dot
+		}
+	}
+	return best
+}
+
+func (f Face) opposite() Face {
+	return f ^ 1
+}
+
+// wantFluid reports whether the fluid in this block should stop the ray.
+func wantFluid(world loader.StateAccess, info loader.ShapeInfo, x, y, z int, mode FluidMode) bool {
+	if !info.Fluid {
+		return false
+	}
+	switch mode {
+	case FluidAny:
+		return true
+	case FluidWater:
+		return info.Water
+	case FluidSourceOnly:
+		return fluidLevel(world, x, y, z) == 0
+	default:
+		return false
+	}
+}
+
+// fluidLevel returns the block's "level" property; states without one
+// (waterlogged blocks) count as sources.
+func fluidLevel(world loader.StateAccess, x, y, z int) int {
+	v, ok := world.StateKeyAt(x, y, z).Props()["level"]
+	if !ok {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// fluidHeight mirrors FlowableFluid.getHeight: a full block when the same
+// fluid sits above, otherwise amount/9 where sources and falling fluid
+// have amount 8 and flowing levels 1-7 have 8-level.
+func fluidHeight(world loader.StateAccess, info loader.ShapeInfo, x, y, z int) float64 {
+	above := world.ShapeAt(x, y+1, z)
+	if above.Fluid && above.Water == info.Water && above.Lava == info.Lava {
+		return 1
+	}
+	amount := 8
+	if lvl := fluidLevel(world, x, y, z); lvl > 0 && lvl < 8 {
+		amount = 8 - lvl
+	}
+	return float64(amount) / 9
+}
+
+func add(a, b loader.Vec3) loader.Vec3 {
+	return loader.Vec3{X: a.X + b.X, Y: a.Y + b.Y, Z: a.Z + b.Z}
+}
+
+func sub(a, b loader.Vec3) loader.Vec3 {
+	return loader.Vec3{X: a.X - b.X, Y: a.Y - b.Y, Z: a.Z - b.Z}
+}
+
+func scale(v loader.Vec3, s float64) loader.Vec3 {
+	return loader.Vec3{X: v.X * s, Y: v.Y * s, Z: v.Z * s}
+}
+
+func lenSq(v loader.Vec3) float64 {
+	return v.X*v.X + v.Y*v.Y + v.Z*v.Z
+}
@@ -0,0 +1,185 @@
+package raycast
+
+import (
+	"math"
+	"testing"
+
+	loader "github.com/reallyoldfogie/mc-data-gen/loader"
+)
+
+var (
+	cube      = []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 1, 1}}}
+	grassBox  = []loader.Box{{Min: [3]float64{0.125, 0, 0.125}, Max: [3]float64{0.875, 0.8125, 0.875}}}
+	slabBox   = []loader.Box{{Min: [3]float64{0, 0, 0}, Max: [3]float64{1, 0.5, 1}}}
+	stoneKey  = loader.StateKey{BlockID: "minecraft:stone"}
+	grassKey  = loader.StateKey{BlockID: "minecraft:short_grass"}
+	waterKey  = loader.StateKey{BlockID: "minecraft:water", PropsKey: "level=0"}
+	flowKey   = loader.StateKey{BlockID: "minecraft:water", PropsKey: "level=3"}
+	slabKey   = loader.StateKey{BlockID: "minecraft:oak_slab", PropsKey: "type=bottom,waterlogged=false"}
+	testTable = map[loader.StateKey]loader.ShapeInfo{
+		{BlockID: "minecraft:air"}: {StateID: 0, Air: true},
+		stoneKey:                   {StateID: 1, Collision: cube, Outline: cube, SolidBlock: true},
+		grassKey:                   {StateID: 2, Outline: grassBox, Replaceable: true},
+		waterKey:                   {StateID: 3, Fluid: true, Water: true},
+		flowKey:                    {StateID: 4, Fluid: true, Water: true},
+		slabKey:                    {StateID: 5, Collision: slabBox, Outline: slabBox, Slab: true},
+	}
+)
+
+// newWorld returns a world with a stone floor at y=0 (top face at y=1)
+// spanning x, z in [-4, 4].
+func newWorld(t *testing.T) *loader.World {
+	t.Helper()
+	table, err := loader.NewStateTable(testTable)
+	if err != nil {
+		t.Fatalf("NewStateTable error: %v", err)
+	}
+	w := loader.NewWorld(table)
+	for x := -4; x <= 4; x++ {
+		for z := -4; z <= 4; z++ {
+			set(t, w, x, 0, z, stoneKey)
+		}
+	}
+	return w
+}
+
+func set(t *testing.T, w *loader.World, x, y, z int, key loader.StateKey) {
+	t.Helper()
+	if err := w.SetState(x, y, z, key); err != nil {
+		t.Fatalf("SetState error: %v", err)
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestRaycastHitsFloor(t *testing.T) {
+	w := newWorld(t)
+	hit, ok := Raycast(w, loader.Vec3{X: 0.5, Y: 3.5, Z: 0.5}, loader.Vec3{X: 1.5, Y: -0.5, Z: 0.5}, Options{})
+	if !ok {
+		t.Fatalf("expected a hit")
+	}
+	if hit.X != 1 || hit.Y != 0 || hit.Z != 0 || hit.Face != FaceUp {
+		t.Fatalf("unexpected hit block/face: %+v", hit)
+	}
+	if !near(hit.Point.Y, 1) || !near(hit.Point.X, 1.125) {
+		t.Fatalf("unexpected hit point %+v", hit.Point)
+	}
+	if hit.State != stoneKey || hit.Inside || hit.Fluid {
+		t.Fatalf("unexpected hit details: %+v", hit)
+	}
+	if !near(hit.Distance, math.Sqrt(0.625*0.625+2.5*2.5)) {
+		t.Fatalf("unexpected distance %f", hit.Distance)
+	}
+}
+
+func TestRaycastMiss(t *testing.T) {
+	w := newWorld(t)
+	if hit, ok := Raycast(w, loader.Vec3{X: 0.5, Y: 5, Z: 0.5}, loader.Vec3{X: 3.5, Y: 1.5, Z: -2}, Options{}); ok {
+		t.Fatalf("expected a miss, got %+v", hit)
+	}
+}
+
+func TestRaycastFaces(t *testing.T) {
+	w := newWorld(t)
+	set(t, w, 2, 1, 0, stoneKey)
+
+	hit, ok := Raycast(w, loader.Vec3{X: 0.5, Y: 1.5, Z: 0.5}, loader.Vec3{X: 4.5, Y: 1.5, Z: 0.5}, Options{})
+	if !ok || hit.X != 2 || hit.Face != FaceWest || !near(hit.Point.X, 2) {
+		t.Fatalf("expected west face of (2,1,0), got %+v (ok=%v)", hit, ok)
+	}
+	hit, ok = Raycast(w, loader.Vec3{X: 4.5, Y: 1.5, Z: 0.5}, loader.Vec3{X: 0.5, Y: 1.5, Z: 0.5}, Options{})
+	if !ok || hit.X != 2 || hit.Face != FaceEast || !near(hit.Point.X, 3) {
+		t.Fatalf("expected east face of (2,1,0), got %+v (ok=%v)", hit, ok)
+	}
+	if dx, dy, dz := hit.Face.Normal(); dx != 1 || dy != 0 || dz != 0 {
+		t.Fatalf("unexpected east normal %d,%d,%d", dx, dy, dz)
+	}
+	if hit.Face.String() != "east" {
+		t.Fatalf("unexpected face name %q", hit.Face)
+	}
+}
+
+func TestRaycastShapeMode(t *testing.T) {
+	w := newWorld(t)
+	set(t, w, 0, 1, 0, grassKey)
+	from := loader.Vec3{X: 0.5, Y: 3, Z: 0.5}
+	to := loader.Vec3{X: 0.5, Y: 0, Z: 0.5}
+
+	hit, ok := Raycast(w, from, to, Options{Shape: ShapeOutline})
+	if !ok || hit.State != grassKey || !near(hit.Point.Y, 1.8125) {
+		t.Fatalf("expected outline hit on grass, got %+v (ok=%v)", hit, ok)
+	}
+	hit, ok = Raycast(w, from, to, Options{Shape: ShapeCollider})
+	if !ok || hit.State != stoneKey || !near(hit.Point.Y, 1) {
+		t.Fatalf("expected collider ray to pass grass and hit stone, got %+v (ok=%v)", hit, ok)
+	}
+}
+
+func TestRaycastSlab(t *testing.T) {
+	w := newWorld(t)
+	set(t, w, 1, 1, 0, slabKey)
+
+	// Passes over the slab's top half, then hits its top face when coming down.
+	hit, ok := Raycast(w, loader.Vec3{X: 0.5, Y: 1.75, Z: 0.5}, loader.Vec3{X: 1.75, Y: 1.25, Z: 0.5}, Options{})
+	if !ok || hit.X != 1 || hit.Y != 1 || hit.Face != FaceUp || !near(hit.Point.Y, 1.5) {
+		t.Fatalf("expected slab top face, got %+v (ok=%v)", hit, ok)
+	}
+}
+
+func TestRaycastFluids(t *testing.T) {
+	w := newWorld(t)
+	set(t, w, 0, 1, 0, waterKey)
+	set(t, w, 1, 1, 0, flowKey)
+	down := func(x float64) (loader.Vec3, loader.Vec3) {
+		return loader.Vec3{X: x, Y: 3, Z: 0.5}, loader.Vec3{X: x, Y: 0, Z: 0.5}
+	}
+
+	from, to := down(0.5)
+	hit, ok := Raycast(w, from, to, Options{Fluids: FluidNone})
+	if !ok || hit.State != stoneKey {
+		t.Fatalf("expected FluidNone to pass through water, got %+v (ok=%v)", hit, ok)
+	}
+	hit, ok = Raycast(w, from, to, Options{Fluids: FluidAny})
+	if !ok || !hit.Fluid || hit.State != waterKey || !near(hit.Point.Y, 1+8.0/9) || hit.Face != FaceUp {
+		t.Fatalf("expected source water surface, got %+v (ok=%v)", hit, ok)
+	}
+	hit, ok = Raycast(w, from, to, Options{Fluids: FluidWater})
+	if !ok || !hit.Fluid {
+		t.Fatalf("expected FluidWater to stop at water, got %+v (ok=%v)", hit, ok)
+	}
+
+	from, to = down(1.5)
+	hit, ok = Raycast(w, from, to, Options{Fluids: FluidAny})
+	if !ok || !hit.Fluid || !near(hit.Point.Y, 1+5.0/9) {
+		t.Fatalf("expected flowing water surface at 5/9, got %+v (ok=%v)", hit, ok)
+	}
+	hit, ok = Raycast(w, from, to, Options{Fluids: FluidSourceOnly})
+	if !ok || hit.Fluid || hit.State != stoneKey {
+		t.Fatalf("expected FluidSourceOnly to skip flowing water, got %+v (ok=%v)", hit, ok)
+	}
+
+	// Water with water above is a full block.
+	set(t, w, 0, 2, 0, waterKey)
+	from, to = down(0.5)
+	hit, ok = Raycast(w, from, to, Options{Fluids: FluidAny})
+	if !ok || hit.Y != 2 || !near(hit.Point.Y, 2+8.0/9) {
+		t.Fatalf("expected top water block surface, got %+v (ok=%v)", hit, ok)
+	}
+	hit, ok = Raycast(w, loader.Vec3{X: 0.5, Y: 2.5, Z: 0.5}, to, Options{Fluids: FluidAny})
+	if !ok || hit.Y != 2 || !hit.Inside {
+		t.Fatalf("expected to start inside the water, got %+v (ok=%v)", hit, ok)
+	}
+}
+
+func TestRaycastStartsInside(t *testing.T) {
+	w := newWorld(t)
+	hit, ok := Raycast(w, loader.Vec3{X: 0.5, Y: 0.5, Z: 0.5}, loader.Vec3{X: 0.5, Y: 3, Z: 0.5}, Options{})
+	if !ok || !hit.Inside || hit.Y != 0 || hit.Face != FaceDown {
+		t.Fatalf("expected inside hit facing down, got %+v (ok=%v)", hit, ok)
+	}
+	if hit.Point != (loader.Vec3{X: 0.5, Y: 0.5, Z: 0.5}) || hit.Distance != 0 {
+		t.Fatalf("expected hit at origin, got %+v", hit)
+	}
+}
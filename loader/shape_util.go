@@ -11,6 +11,44 @@ type AABB struct {
 	Max Vec3
 }
 
+// Offset returns the box translated by (dx, dy, dz).
+func (a AABB) Offset(dx, dy, dz float64) AABB {
+	return AABB{
+		Min: Vec3{X: a.Min.X + dx, Y: a.Min.Y + dy, Z: a.Min.Z + dz},
+		Max: Vec3{X: a.Max.X + dx, Y: a.Max.Y + dy, Z: a.Max.Z + dz},
+	}
+}
+
+// Stretch grows the box in the direction of (dx, dy, dz), so that it covers
+// everything swept by moving the original box by that amount.
+func (a AABB) Stretch(dx, dy, dz float64) AABB {
+	out := a
+	if dx < 0 {
+		out.Min.X += dx
+	} else {
+		out.Max.X += dx
+	}
+	if dy < 0 {
+		out.Min.Y += dy
+	} else {
+		out.Max.Y += dy
+	}
+	if dz < 0 {
+		out.Min.Z += dz
+	} else {
+		out.Max.Z += dz
+	}
+	return out
+}
+
+// Intersects reports whether two boxes overlap with non-zero volume.
+// Boxes that only touch on a face do not intersect.
+func (a AABB) Intersects(b AABB) bool {
+	return a.Min.X < b.Max.X && a.Max.X > b.Min.X &&
+		a.Min.Y < b.Max.Y && a.Max.Y > b.Min.Y &&
+		a.Min.Z < b.Max.Z && a.Max.Z > b.Min.Z
+}
+
 // IsPassable reports whether the blockstate should be considered passable
 // for entity movement. This is based purely on collision, not outline.
 func (info ShapeInfo) IsPassable() bool {
@@ -0,0 +1,103 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// String formats the key in Minecraft's block-state syntax, e.g.
+// "minecraft:oak_stairs[facing=north,half=top]". Blocks without properties
+// format as the bare ID.
+func (k StateKey) String() string {
+	if k.PropsKey == "" {
+		return k.BlockID
+	}
+	return k.BlockID + "[" + k.PropsKey + "]"
+}
+
+// Props decodes PropsKey back into a property map. It returns nil for a
+// block without properties.
+func (k StateKey) Props() map[string]string {
+	if k.PropsKey == "" {
+		return nil
+	}
+	parts := strings.Split(k.PropsKey, ",")
+	out := make(map[string]string, len(parts))
+	for _, p := range parts {
+		name, value, _ := strings.Cut(p, "=")
+		out[name] = value
+	}
+	return out
+}
+
+// ParseStateString parses a block-state string such as
+// "minecraft:oak_stairs[facing=north,half=top]" or "stone" into a StateKey.
+// A missing namespace defaults to "minecraft".
+//
+// defs is optional. When it is non-nil the block must exist in it, every
+// property is validated against the block's schema, and properties left out
+// are filled from the default state when one is recorded.
+func ParseStateString(s string, defs map[string]*BlockDef) (StateKey, error) {
+	s = strings.TrimSpace(s)
+	id := s
+	var propsPart string
+	hasProps := false
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		if !strings.HasSuffix(s, "]") {
+			return StateKey{}, fmt.Errorf("parse state %q: missing closing ']'", s)
+		}
+		id = s[:i]
+		propsPart = s[i+1 : len(s)-1]
+		hasProps = true
+	}
+	if id == "" {
+		return StateKey{}, fmt.Errorf("parse state %q: empty block id", s)
+	}
+	if strings.ContainsAny(id, "[]=,") {
+		return StateKey{}, fmt.Errorf("parse state %q: invalid block id %q", s, id)
+	}
+	id = normalizeID(id)
+
+	props := make(map[string]string)
+	if hasProps && strings.TrimSpace(propsPart) != "" {
+		for _, pair := range strings.Split(propsPart, ",") {
+			name, value, ok := strings.Cut(pair, "=")
+			name = strings.TrimSpace(name)
+			value = strings.TrimSpace(value)
+			if !ok || name == "" || value == "" {
+				return StateKey{}, fmt.Errorf("parse state %q: malformed property %q", s, pair)
+			}
+			if _, dup := props[name]; dup {
+				return StateKey{}, fmt.Errorf("parse state %q: property %s given twice", s, name)
+			}
+			props[name] = value
+		}
+	}
+
+	if defs != nil {
+		def, ok := defs[id]
+		if !ok {
+			return StateKey{}, fmt.Errorf("parse state %q: unknown block %s", s, id)
+		}
+		if err := def.ValidateProps(props); err != nil {
+			return StateKey{}, fmt.Errorf("parse state %q: %w", s, err)
+		}
+		if def.DefaultState() != nil {
+			full, err := def.CompleteProps(props)
+			if err != nil {
+				return StateKey{}, fmt.Errorf("parse state %q: %w", s, err)
+			}
+			props = full
+		}
+	}
+
+	return StateKey{BlockID: id, PropsKey: MakePropsKey(props)}, nil
+}
+
+// normalizeID adds the default "minecraft" namespace to a bare ID.
+func normalizeID(id string) string {
+	if !strings.Contains(id, ":") {
+		return "minecraft:" + id
+	}
+	return id
+}
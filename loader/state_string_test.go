@@ -0,0 +1,94 @@
+package loader
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStateStringRoundTrip(t *testing.T) {
+	cases := []struct {
+		in   string
+		want StateKey
+		str  string
+	}{
+		{
+			in:   "minecraft:oak_stairs[half=top,facing=north]",
+			want: StateKey{BlockID: "minecraft:oak_stairs", PropsKey: "facing=north,half=top"},
+			str:  "minecraft:oak_stairs[facing=north,half=top]",
+		},
+		{
+			in:   "stone",
+			want: StateKey{BlockID: "minecraft:stone", PropsKey: ""},
+			str:  "minecraft:stone",
+		},
+		{
+			in:   "mymod:thing[]",
+			want: StateKey{BlockID: "mymod:thing", PropsKey: ""},
+			str:  "mymod:thing",
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := ParseStateString(tc.in, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: got %+v want %+v", tc.in, got, tc.want)
+		}
+		if got.String() != tc.str {
+			t.Fatalf("%s: String() = %q want %q", tc.in, got.String(), tc.str)
+		}
+		again, err := ParseStateString(got.String(), nil)
+		if err != nil || again != got {
+			t.Fatalf("%s: round trip gave %+v (err %v)", tc.in, again, err)
+		}
+	}
+}
+
+func TestParseStateStringErrors(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"[facing=north]",
+		"minecraft:oak_stairs[facing=north",
+		"minecraft:oak_stairs[facing]",
+		"minecraft:oak_stairs[facing=north,facing=south]",
+	} {
+		if _, err := ParseStateString(in, nil); err == nil {
+			t.Fatalf("%q: expected error", in)
+		}
+	}
+}
+
+func TestParseStateStringWithDefs(t *testing.T) {
+	defs, err := LoadBlockDefsDir(filepath.Join("testdata", "blocks"))
+	if err != nil {
+		t.Fatalf("LoadBlockDefsDir error: %v", err)
+	}
+
+	key, err := ParseStateString("oak_slab[type=top]", defs)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.PropsKey != "type=top,waterlogged=false" {
+		t.Fatalf("expected defaults to be filled in, got %q", key.PropsKey)
+	}
+
+	if _, err := ParseStateString("oak_slab[facing=north]", defs); err == nil {
+		t.Fatalf("expected error for unknown property")
+	}
+	if _, err := ParseStateString("minecraft:not_a_block", defs); err == nil {
+		t.Fatalf("expected error for unknown block")
+	}
+}
+
+func TestStateKeyProps(t *testing.T) {
+	k := StateKey{BlockID: "minecraft:oak_slab", PropsKey: "type=top,waterlogged=false"}
+	props := k.Props()
+	if props["type"] != "top" || props["waterlogged"] != "false" || len(props) != 2 {
+		t.Fatalf("unexpected props: %v", props)
+	}
+	if MakePropsKey(props) != k.PropsKey {
+		t.Fatalf("Props did not round-trip through MakePropsKey")
+	}
+}
@@ -0,0 +1,96 @@
+package loader
+
+import "fmt"
+
+// StateTable is a dense, ID-indexed view of a block map. It is meant for
+// hot paths that work with global palette IDs straight off the wire, where
+// building a PropsKey string per lookup would be wasteful.
+type StateTable struct {
+	shapes  []ShapeInfo
+	keys    []StateKey
+	present []bool
+	ids     map[StateKey]int
+}
+
+// NewStateTable builds a StateTable from a map returned by LoadBlocksDir.
+// Every state must carry a distinct StateID; data exported before state IDs
+// were recorded has all IDs at zero and is rejected.
+func NewStateTable(blocks map[StateKey]ShapeInfo) (*StateTable, error) {
+	maxID := -1
+	for k, info := range blocks {
+		if info.StateID < 0 {
+			return nil, fmt.Errorf("negative state id %d for %s[%s]", info.StateID, k.BlockID, k.PropsKey)
+		}
+		if info.StateID > maxID {
+			maxID = info.StateID
+		}
+	}
+
+	t := &StateTable{
+		shapes:  make([]ShapeInfo, maxID+1),
+		keys:    make([]StateKey, maxID+1),
+		present: make([]bool, maxID+1),
+		ids:     make(map[StateKey]int, len(blocks)),
+	}
+	for k, info := range blocks {
+		id := info.StateID
+		if t.present[id] {
+			prev := t.keys[id]
+			return nil, fmt.Errorf("state id %d assigned to both %s[%s] and %s[%s] (data missing state_id?)",
+				id, prev.BlockID, prev.PropsKey, k.BlockID, k.PropsKey)
+		}
+		t.shapes[id] = info
+		t.keys[id] = k
+		t.present[id] = true
+		t.ids[k] = id
+	}
+	return t, nil
+}
+
+// LoadStateTableDir loads a blocks directory and builds a StateTable from it.
+func LoadStateTableDir(root string) (*StateTable, error) {
+	blocks, err := LoadBlocksDir(root)
+	if err != nil {
+		return nil, err
+	}
+	return NewStateTable(blocks)
+}
+
+// Len returns the size of the ID space (highest state ID + 1).
+func (t *StateTable) Len() int {
+	return len(t.shapes)
+}
+
+// ByID returns the ShapeInfo for a global state ID. Unknown IDs return the
+// zero ShapeInfo; use Lookup to tell them apart.
+func (t *StateTable) ByID(id int) ShapeInfo {
+	if id < 0 || id >= len(t.shapes) {
+		return ShapeInfo{}
+	}
+	return t.shapes[id]
+}
+
+// Lookup returns the ShapeInfo for a global state ID and whether it exists.
+func (t *StateTable) Lookup(id int) (ShapeInfo, bool) {
+	if id < 0 || id >= len(t.shapes) || !t.present[id] {
+		return ShapeInfo{}, false
+	}
+	return t.shapes[id], true
+}
+
+// KeyOf returns the StateKey for a global state ID.
+func (t *StateTable) KeyOf(id int) (StateKey, bool) {
+	if id < 0 || id >= len(t.keys) || !t.present[id] {
+		return StateKey{}, false
+	}
+	return t.keys[id], true
+}
+
+// IDOf returns the global state ID for a StateKey, or -1 if it is unknown.
+func (t *StateTable) IDOf(key StateKey) int {
+	id, ok := t.ids[key]
+	if !ok {
+		return -1
+	}
+	return id
+}
@@ -0,0 +1,50 @@
+package loader
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadStateTableDir(t *testing.T) {
+	table, err := LoadStateTableDir(filepath.Join("testdata", "blocks"))
+	if err != nil {
+		t.Fatalf("LoadStateTableDir error: %v", err)
+	}
+	if table.Len() != 26 {
+		t.Fatalf("expected Len 26, got %d", table.Len())
+	}
+
+	stone := StateKey{BlockID: "minecraft:stone", PropsKey: ""}
+	if id := table.IDOf(stone); id != 1 {
+		t.Fatalf("expected stone id 1, got %d", id)
+	}
+	if info := table.ByID(1); !info.SolidBlock || info.Hardness != 1.5 {
+		t.Fatalf("unexpected ShapeInfo for id 1: %+v", info)
+	}
+	if key, ok := table.KeyOf(10); !ok || key.BlockID != "minecraft:dirt" || key.PropsKey != "variant=coarse" {
+		t.Fatalf("unexpected key for id 10: %+v (ok=%v)", key, ok)
+	}
+
+	if _, ok := table.Lookup(5); ok {
+		t.Fatalf("expected gap id 5 to be absent")
+	}
+	if _, ok := table.Lookup(-1); ok {
+		t.Fatalf("expected negative id to be absent")
+	}
+	if info := table.ByID(1000); info.StateID != 0 || info.Collision != nil {
+		t.Fatalf("expected zero ShapeInfo for out-of-range id, got %+v", info)
+	}
+	if id := table.IDOf(StateKey{BlockID: "minecraft:nope"}); id != -1 {
+		t.Fatalf("expected -1 for unknown key, got %d", id)
+	}
+}
+
+func TestNewStateTableDuplicateID(t *testing.T) {
+	blocks := map[StateKey]ShapeInfo{
+		{BlockID: "minecraft:stone"}: {},
+		{BlockID: "minecraft:dirt"}:  {},
+	}
+	if _, err := NewStateTable(blocks); err == nil {
+		t.Fatalf("expected error for duplicate state ids")
+	}
+}
@@ -0,0 +1,253 @@
+package loader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// TagIndex answers tag membership for one registry (blocks, items or
+// entity types). Tag and object IDs are normalized, so "#logs",
+// "logs" and "minecraft:logs" are the same tag.
+type TagIndex struct {
+	// children holds the tags each tag directly includes via "#tag"
+	// entries. Indexes built from membership alone have none.
+	children map[string][]string
+	members  map[string][]string
+	tags     map[string][]string
+}
+
+// NewTagIndex builds an index from tag definitions as datapacks declare
+// them: tag -> entries, where an entry is an object ID or a "#tag"
+// reference, optionally suffixed with "?" when it is not required.
+// References are expanded recursively. A required reference to an unknown
+// tag, or a tag that includes itself, is an error.
+func NewTagIndex(defs map[string][]string) (*TagIndex, error) {
+	declared := make(map[string]bool, len(defs))
+	for tag := range defs {
+		declared[NormalizeTag(tag)] = true
+	}
+	direct := make(map[string][]string, len(defs))
+	children := make(map[string][]string, len(defs))
+	for tag, entries := range defs {
+		tag = NormalizeTag(tag)
+		if _, ok := direct[tag]; !ok {
+			direct[tag] = nil
+		}
+		for _, e := range entries {
+			optional := strings.HasSuffix(e, "?")
+			e = strings.TrimSuffix(e, "?")
+			if !strings.HasPrefix(e, "#") {
+				direct[tag] = append(direct[tag], normalizeID(e))
+				continue
+			}
+			ref := NormalizeTag(e)
+			if !declared[ref] {
+				if optional {
+					continue
+				}
+				return nil, fmt.Errorf("tag %s references unknown tag %s", tag, ref)
+			}
+			children[tag] = append(children[tag], ref)
+		}
+	}
+
+	t := &TagIndex{
+		children: children,
+		members:  make(map[string][]string, len(direct)),
+		tags:     make(map[string][]string),
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(direct))
+	var stack []string
+	var resolve func(tag string) error
+	resolve = func(tag string) error {
+		switch state[tag] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("tag cycle: %s -> %s", strings.Join(stack, " -> "), tag)
+		}
+		state[tag] = visiting
+		stack = append(stack, tag)
+		set := make(map[string]bool)
+		for _, id := range direct[tag] {
+			set[id] = true
+		}
+		for _, child := range children[tag] {
+			if err := resolve(child); err != nil {
+				return err
+			}
+			for _, id := range t.members[child] {
+				set[id] = true
+			}
+		}
+		t.members[tag] = sortedKeys(set)
+		stack = stack[:len(stack)-1]
+		state[tag] = done
+		return nil
+	}
+	for tag := range direct {
+		if err := resolve(tag); err != nil {
+			return nil, err
+		}
+	}
+
+	for tag, ids := range t.members {
+		for _, id := range ids {
+			t.tags[id] = append(t.tags[id], tag)
+		}
+	}
+	for id := range t.tags {
+		sort.Strings(t.tags[id])
+	}
+	for tag := range t.children {
+		sort.Strings(t.children[tag])
+	}
+	return t, nil
+}
+
+// TagIndexFromMembership builds an index from each object's flattened tag
+// list (object ID -> tags), as the per-object files carry them. It has no
+// hierarchy, so Children is always empty.
+func TagIndexFromMembership(membership map[string][]string) *TagIndex {
+	sets := make(map[string]map[string]bool)
+	t := &TagIndex{
+		children: map[string][]string{},
+		members:  make(map[string][]string),
+		tags:     make(map[string][]string, len(membership)),
+	}
+	for id, tags := range membership {
+		id = normalizeID(id)
+		for _, tag := range tags {
+			tag = NormalizeTag(tag)
+			if sets[tag] == nil {
+				sets[tag] = make(map[string]bool)
+			}
+			sets[tag][id] = true
+		}
+	}
+	for tag, set := range sets {
+		ids := sortedKeys(set)
+		t.members[tag] = ids
+		for _, id := range ids {
+			t.tags[id] = append(t.tags[id], tag)
+		}
+	}
+	for id := range t.tags {
+		sort.Strings(t.tags[id])
+	}
+	return t
+}
+
+// Has reports whether id is in tag, directly or through a nested tag.
+func (t *TagIndex) Has(id, tag string) bool {
+	members := t.members[NormalizeTag(tag)]
+	id = normalizeID(id)
+	i := sort.SearchStrings(members, id)
+	return i < len(members) && members[i] == id
+}
+
+// Members returns the sorted IDs in tag, nested tags expanded. The slice
+// is shared; callers must not modify it.
+func (t *TagIndex) Members(tag string) []string {
+	return t.members[NormalizeTag(tag)]
+}
+
+// TagsOf returns the sorted tags id is in.
+func (t *TagIndex) TagsOf(id string) []string {
+	return t.tags[normalizeID(id)]
+}
+
+// Children returns the tags tag directly includes, sorted.
+func (t *TagIndex) Children(tag string) []string {
+	return t.children[NormalizeTag(tag)]
+}
+
+// Expand returns tag and every tag it includes, however deeply nested,
+// sorted. It is nil for unknown tags.
+func (t *TagIndex) Expand(tag string) []string {
+	tag = NormalizeTag(tag)
+	if _, ok := t.members[tag]; !ok {
+		return nil
+	}
+	seen := map[string]bool{tag: true}
+	queue := []string{tag}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, child := range t.children[cur] {
+			if !seen[child] {
+				seen[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// Names returns every tag in the index, sorted.
+func (t *TagIndex) Names() []string {
+	names := make([]string, 0, len(t.members))
+	for tag := range t.members {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeTag strips a leading "#" and defaults the namespace to
+// minecraft, so "#logs" becomes "minecraft:logs".
+func NormalizeTag(tag string) string {
+	return normalizeID(strings.TrimPrefix(tag, "#"))
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LoadTags reads a tags.json file (registry -> tag -> entries) and builds
+// one TagIndex per registry, keyed "block", "item", "entity_type".
+func LoadTags(path string) (map[string]*TagIndex, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return decodeTags(path, data)
+}
+
+// LoadTagsFS is LoadTags for a file inside an fs.FS.
+func LoadTagsFS(fsys fs.FS, name string) (map[string]*TagIndex, error) {
+	data, err := readFileFS(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTags(name, data)
+}
+
+func decodeTags(name string, data []byte) (map[string]*TagIndex, error) {
+	var raw map[string]map[string][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshal %s: %w", name, err)
+	}
+	out := make(map[string]*TagIndex, len(raw))
+	for registry, defs := range raw {
+		idx, err := NewTagIndex(defs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", name, registry, err)
+		}
+		out[registry] = idx
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package loader
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTagIndexExpandsNestedTags(t *testing.T) {
+	idx, err := NewTagIndex(map[string][]string{
+		"minecraft:logs":             {"#minecraft:oak_logs", "#birch_logs", "#minecraft:modded_logs?"},
+		"minecraft:oak_logs":         {"minecraft:oak_log", "oak_wood"},
+		"minecraft:birch_logs":       {"minecraft:birch_log"},
+		"minecraft:logs_that_burn":   {"#minecraft:logs"},
+		"minecraft:mineable/axe":     {"#logs", "minecraft:chest"},
+		"minecraft:completely_empty": {},
+	})
+	if err != nil {
+		t.Fatalf("NewTagIndex error: %v", err)
+	}
+
+	want := []string{"minecraft:birch_log", "minecraft:oak_log", "minecraft:oak_wood"}
+	if got := idx.Members("#minecraft:logs_that_burn"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if !idx.Has("oak_log", "#logs") || !idx.Has("minecraft:chest", "mineable/axe") {
+		t.Fatalf("expected normalized lookups to succeed")
+	}
+	if idx.Has("minecraft:chest", "minecraft:logs") || idx.Has("minecraft:oak_log", "minecraft:nope") {
+		t.Fatalf("unexpected membership")
+	}
+
+	wantTags := []string{"minecraft:logs", "minecraft:logs_that_burn", "minecraft:mineable/axe", "minecraft:oak_logs"}
+	if got := idx.TagsOf("minecraft:oak_log"); !reflect.DeepEqual(got, wantTags) {
+		t.Fatalf("expected tags %v, got %v", wantTags, got)
+	}
+	wantChildren := []string{"minecraft:birch_logs", "minecraft:oak_logs"}
+	if got := idx.Children("logs"); !reflect.DeepEqual(got, wantChildren) {
+		t.Fatalf("expected children %v, got %v", wantChildren, got)
+	}
+	wantExpand := []string{"minecraft:birch_logs", "minecraft:logs", "minecraft:mineable/axe", "minecraft:oak_logs"}
+	if got := idx.Expand("#minecraft:mineable/axe"); !reflect.DeepEqual(got, wantExpand) {
+		t.Fatalf("expected expansion %v, got %v", wantExpand, got)
+	}
+	if idx.Expand("minecraft:nope") != nil {
+		t.Fatalf("expected nil expansion for unknown tag")
+	}
+	if got := idx.Members("minecraft:completely_empty"); len(got) != 0 {
+		t.Fatalf("expected empty tag, got %v", got)
+	}
+	if n := len(idx.Names()); n != 6 {
+		t.Fatalf("expected 6 tags, got %d", n)
+	}
+}
+
+func TestTagIndexErrors(t *testing.T) {
+	_, err := NewTagIndex(map[string][]string{
+		"minecraft:a": {"#minecraft:b"},
+		"minecraft:b": {"#minecraft:c"},
+		"minecraft:c": {"#minecraft:a"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+
+	_, err = NewTagIndex(map[string][]string{"minecraft:a": {"#minecraft:missing"}})
+	if err == nil || !strings.Contains(err.Error(), "minecraft:missing") {
+		t.Fatalf("expected unknown tag error, got %v", err)
+	}
+}
+
+func TestTagIndexFromMembership(t *testing.T) {
+	idx := TagIndexFromMembership(map[string][]string{
+		"minecraft:iron_sword":   {"minecraft:swords", "minecraft:enchantable/sword"},
+		"minecraft:golden_sword": {"minecraft:swords", "minecraft:piglin_loved"},
+		"minecraft:apple":        nil,
+	})
+	want := []string{"minecraft:golden_sword", "minecraft:iron_sword"}
+	if got := idx.Members("swords"); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if !idx.Has("minecraft:golden_sword", "#piglin_loved") || idx.Has("minecraft:apple", "minecraft:swords") {
+		t.Fatalf("unexpected membership")
+	}
+	if got := idx.Expand("minecraft:swords"); !reflect.DeepEqual(got, []string{"minecraft:swords"}) {
+		t.Fatalf("expected a flat expansion, got %v", got)
+	}
+}
+
+func TestLoadTags(t *testing.T) {
+	tags, err := LoadTags(filepath.Join("testdata", "tags.json"))
+	if err != nil {
+		t.Fatalf("LoadTags error: %v", err)
+	}
+	if !tags["block"].Has("minecraft:stone", "minecraft:stone_ore_replaceables") {
+		t.Fatalf("expected stone in stone_ore_replaceables through base_stone_overworld")
+	}
+	if !tags["item"].Has("minecraft:iron_sword", "minecraft:enchantable/sword") {
+		t.Fatalf("expected iron_sword in enchantable/sword")
+	}
+	// The optional #minecraft:skeletons reference is undefined and skipped.
+	if got := tags["entity_type"].Members("minecraft:undead"); !reflect.DeepEqual(got, []string{"minecraft:zombie"}) {
+		t.Fatalf("unexpected undead members %v", got)
+	}
+}
+
+func TestShapeInfoHasTag(t *testing.T) {
+	info := ShapeInfo{Tags: []string{"minecraft:logs"}, Material: []string{"mineable/axe"}}
+	if !info.HasTag("#logs") {
+		t.Fatalf("expected exported tags to be used")
+	}
+	if info.HasTag("minecraft:mineable/axe") {
+		t.Fatalf("expected Material to be ignored once tags are exported")
+	}
+	old := ShapeInfo{Material: []string{"mineable/axe"}}
+	if !old.HasTag("minecraft:mineable/axe") || old.HasTag("minecraft:logs") {
+		t.Fatalf("expected old data to fall back to the mining tags")
+	}
+}
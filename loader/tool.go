@@ -0,0 +1,91 @@
+package loader
+
+import "strings"
+
+// ToolComponent is an item's minecraft:tool component: which blocks it
+// mines faster and which it is the correct tool for.
+type ToolComponent struct {
+	Rules              []ToolRule `json:"rules"`
+	DefaultMiningSpeed float64    `json:"default_mining_speed"`
+	DamagePerBlock     int        `json:"damage_per_block"`
+}
+
+// ToolRule applies to the blocks in Tag, or to the listed Blocks when the
+// rule names them directly. Speed and CorrectForDrops are nil when the
+// rule does not set them.
+type ToolRule struct {
+	Tag             string   `json:"tag,omitempty"`
+	Blocks          []string `json:"blocks,omitempty"`
+	Speed           *float64 `json:"speed,omitempty"`
+	CorrectForDrops *bool    `json:"correct_for_drops,omitempty"`
+}
+
+// Matches reports whether the rule applies to state.
+func (r ToolRule) Matches(state ShapeInfo) bool {
+	if r.Tag != "" {
+		return state.HasTag(r.Tag)
+	}
+	return containsString(r.Blocks, state.BlockID)
+}
+
+// MiningSpeed returns the speed of the first matching rule that sets one,
+// or DefaultMiningSpeed.
+func (t *ToolComponent) MiningSpeed(state ShapeInfo) float64 {
+	for _, r := range t.Rules {
+		if r.Speed != nil && r.Matches(state) {
+			return *r.Speed
+		}
+	}
+	return t.DefaultMiningSpeed
+}
+
+// CorrectForDrops reports whether the first matching rule that decides
+// drops allows them. Tools with no such rule are never correct.
+func (t *ToolComponent) CorrectForDrops(state ShapeInfo) bool {
+	for _, r := range t.Rules {
+		if r.CorrectForDrops != nil && r.Matches(state) {
+			return *r.CorrectForDrops
+		}
+	}
+	return false
+}
+
+// incorrectFor maps the vanilla incorrect_for_<tier>_tool tags to the
+// needs_<tier>_tool tags they are built from.
+var incorrectFor = map[string][]string{
+	"wooden":    {"needs_stone_tool", "needs_iron_tool", "needs_diamond_tool"},
+	"gold":      {"needs_stone_tool", "needs_iron_tool", "needs_diamond_tool"},
+	"stone":     {"needs_iron_tool", "needs_diamond_tool"},
+	"copper":    {"needs_iron_tool", "needs_diamond_tool"},
+	"iron":      {"needs_diamond_tool"},
+	"diamond":   nil,
+	"netherite": nil,
+}
+
+// HasTag reports whether the block is in tag, e.g. "#minecraft:logs".
+// Data exported before block tags were only knows the mining tags, so it
+// falls back to HasMiningTag.
+func (info ShapeInfo) HasTag(tag string) bool {
+	if len(info.Tags) == 0 {
+		return info.HasMiningTag(tag)
+	}
+	return containsString(info.Tags, NormalizeTag(tag))
+}
+
+// HasMiningTag reports whether the block is in one of the block tags tool
+// rules refer to, as far as Material records: minecraft:mineable/<tool>,
+// minecraft:needs_<tier>_tool and minecraft:incorrect_for_<tier>_tool.
+// Other tags report false.
+func (info ShapeInfo) HasMiningTag(tag string) bool {
+	name := strings.TrimPrefix(strings.TrimPrefix(tag, "#"), "minecraft:")
+	if tier, ok := strings.CutPrefix(name, "incorrect_for_"); ok {
+		tier = strings.TrimSuffix(tier, "_tool")
+		for _, needs := range incorrectFor[tier] {
+			if containsString(info.Material, needs) {
+				return true
+			}
+		}
+		return false
+	}
+	return containsString(info.Material, name)
+}
@@ -0,0 +1,82 @@
+package loader
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+var ironPickaxeTool = &ToolComponent{
+	Rules: []ToolRule{
+		{Tag: "minecraft:incorrect_for_iron_tool", CorrectForDrops: ptr(false)},
+		{Tag: "minecraft:mineable/pickaxe", Speed: ptr(6.0), CorrectForDrops: ptr(true)},
+	},
+	DefaultMiningSpeed: 1,
+	DamagePerBlock:     1,
+}
+
+func TestToolComponentRules(t *testing.T) {
+	stone := ShapeInfo{BlockID: "minecraft:stone", Material: []string{"mineable/pickaxe"}}
+	obsidian := ShapeInfo{BlockID: "minecraft:obsidian", Material: []string{"mineable/pickaxe", "needs_diamond_tool"}}
+	dirt := ShapeInfo{BlockID: "minecraft:dirt", Material: []string{"mineable/shovel"}}
+
+	if s := ironPickaxeTool.MiningSpeed(stone); s != 6 {
+		t.Fatalf("expected speed 6 on stone, got %v", s)
+	}
+	if !ironPickaxeTool.CorrectForDrops(stone) {
+		t.Fatalf("expected iron pickaxe to be correct for stone")
+	}
+	// The deny rule comes first, but it sets no speed, so obsidian still
+	// mines at pickaxe speed without dropping.
+	if s := ironPickaxeTool.MiningSpeed(obsidian); s != 6 {
+		t.Fatalf("expected speed 6 on obsidian, got %v", s)
+	}
+	if ironPickaxeTool.CorrectForDrops(obsidian) {
+		t.Fatalf("expected iron pickaxe to be incorrect for obsidian")
+	}
+	if s := ironPickaxeTool.MiningSpeed(dirt); s != 1 || ironPickaxeTool.CorrectForDrops(dirt) {
+		t.Fatalf("expected default speed and no drops on dirt, got %v", s)
+	}
+}
+
+func TestHasMiningTag(t *testing.T) {
+	ore := ShapeInfo{Material: []string{"mineable/pickaxe", "needs_iron_tool"}}
+	cases := map[string]bool{
+		"minecraft:mineable/pickaxe":           true,
+		"#minecraft:mineable/pickaxe":          true,
+		"minecraft:mineable/axe":               false,
+		"minecraft:needs_iron_tool":            true,
+		"minecraft:incorrect_for_wooden_tool":  true,
+		"minecraft:incorrect_for_stone_tool":   true,
+		"minecraft:incorrect_for_iron_tool":    false,
+		"minecraft:incorrect_for_diamond_tool": false,
+		"minecraft:sword_efficient":            false,
+	}
+	for tag, want := range cases {
+		if got := ore.HasMiningTag(tag); got != want {
+			t.Errorf("HasMiningTag(%q) = %v, want %v", tag, got, want)
+		}
+	}
+}
+
+func TestLoadItemToolComponent(t *testing.T) {
+	info, err := LoadItemFile(filepath.Join("testdata", "items", "minecraft", "iron_sword.json"))
+	if err != nil {
+		t.Fatalf("LoadItemFile error: %v", err)
+	}
+	tool := info.Components.Tool
+	if tool == nil {
+		t.Fatalf("expected a tool component")
+	}
+	if len(tool.Rules) != 3 || tool.DefaultMiningSpeed != 1 || tool.DamagePerBlock != 2 {
+		t.Fatalf("unexpected tool component %+v", tool)
+	}
+	cobweb := ShapeInfo{BlockID: "minecraft:cobweb"}
+	if s := tool.MiningSpeed(cobweb); s != 15 || !tool.CorrectForDrops(cobweb) {
+		t.Fatalf("expected sword to cut cobweb at 15, got %v", s)
+	}
+	if s := tool.MiningSpeed(ShapeInfo{BlockID: "minecraft:stone"}); s != 1 {
+		t.Fatalf("expected default speed on stone, got %v", s)
+	}
+}
@@ -0,0 +1,273 @@
+package loader
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// BlockAccess is the shared view of a block world used by spatial helpers
+// (physics, raycasting, pathfinding): give it a block position, get back
+// the ShapeInfo of the state there.
+type BlockAccess interface {
+	ShapeAt(x, y, z int) ShapeInfo
+}
+
+// BlockAccessFunc adapts a plain function to BlockAccess.
+type BlockAccessFunc func(x, y, z int) ShapeInfo
+
+// ShapeAt calls f(x, y, z).
+func (f BlockAccessFunc) ShapeAt(x, y, z int) ShapeInfo {
+	return f(x, y, z)
+}
+
+// StateAccess is a BlockAccess that can also name the state at a position,
+// for callers that report results as StateKeys (e.g. raycasting).
+type StateAccess interface {
+	BlockAccess
+	StateKeyAt(x, y, z int) StateKey
+}
+
+const (
+	sectionSize    = 16
+	sectionVolume  = sectionSize * sectionSize * sectionSize
+	minIndirectBit = 4
+	maxIndirectBit = 8
+)
+
+// World is a sparse, in-memory block world made of 16x16x16 chunk
+// sections. Each section stores palette-compressed global state IDs the
+// same way the game's PalettedContainer does, and lookups resolve through
+// a StateTable. Positions in sections that were never written read as
+// state ID 0 (air in vanilla).
+type World struct {
+	states     *StateTable
+	globalBits uint
+	sections   map[[3]int]*section
+}
+
+// NewWorld creates an empty World backed by the given state table.
+func NewWorld(states *StateTable) *World {
+	gb := uint(bits.Len(uint(states.Len() - 1)))
+	if gb == 0 {
+		gb = 1
+	}
+	return &World{
+		states:     states,
+		globalBits: gb,
+		sections:   make(map[[3]int]*section),
+	}
+}
+
+// States returns the table used to resolve state IDs.
+func (w *World) States() *StateTable {
+	return w.states
+}
+
+// ShapeAt implements BlockAccess.
+func (w *World) ShapeAt(x, y, z int) ShapeInfo {
+	return w.states.ByID(w.StateIDAt(x, y, z))
+}
+
+// StateKeyAt implements StateAccess. Positions holding an ID the table does
+// not know (such as air in a table without it) return the zero StateKey.
+func (w *World) StateKeyAt(x, y, z int) StateKey {
+	key, _ := w.states.KeyOf(w.StateIDAt(x, y, z))
+	return key
+}
+
+// StateIDAt returns the global state ID stored at a block position.
+func (w *World) StateIDAt(x, y, z int) int {
+	s, ok := w.sections[[3]int{x >> 4, y >> 4, z >> 4}]
+	if !ok {
+		return 0
+	}
+	return s.get(sectionIndex(x, y, z))
+}
+
+// SetStateID stores a global state ID at a block position. ID 0 is always
+// accepted so positions can be cleared back to air.
+func (w *World) SetStateID(x, y, z, id int) error {
+	if !w.known(id) {
+		return fmt.Errorf("unknown state id %d", id)
+	}
+	key := [3]int{x >> 4, y >> 4, z >> 4}
+	s, ok := w.sections[key]
+	if !ok {
+		if id == 0 {
+			return nil
+		}
+		s = newSection(0, w.globalBits)
+		w.sections[key] = s
+	}
+	s.set(sectionIndex(x, y, z), id)
+	return nil
+}
+
+// SetState stores the state identified by key at a block position.
+func (w *World) SetState(x, y, z int, key StateKey) error {
+	id := w.states.IDOf(key)
+	if id < 0 {
+		return fmt.Errorf("unknown state %s", key)
+	}
+	return w.SetStateID(x, y, z, id)
+}
+
+// LoadSection replaces a whole section (section coordinates, i.e. block
+// coordinates >> 4) with data as read from a chunk packet. bitsPerEntry 0
+// means a single-valued section whose value is palette[0]; a nil palette
+// means data holds global state IDs directly.
+func (w *World) LoadSection(sx, sy, sz int, bitsPerEntry int, palette []int, data []uint64) error {
+	if bitsPerEntry < 0 || bitsPerEntry > 32 {
+		return fmt.Errorf("invalid bits per entry %d", bitsPerEntry)
+	}
+	b := uint(bitsPerEntry)
+	if b == 0 {
+		if len(palette) != 1 {
+			return fmt.Errorf("single-valued section needs exactly one palette entry, got %d", len(palette))
+		}
+	} else {
+		perLong := 64 / int(b)
+		want := (sectionVolume + perLong - 1) / perLong
+		if len(data) != want {
+			return fmt.Errorf("expected %d longs for %d bits per entry, got %d", want, b, len(data))
+		}
+	}
+	for _, id := range palette {
+		if !w.known(id) {
+			return fmt.Errorf("palette references unknown state id %d", id)
+		}
+	}
+
+	s := &section{bits: b, direct: palette == nil, globalBits: w.globalBits}
+	s.palette = append([]int(nil), palette...)
+	if b == 0 {
+		w.sections[[3]int{sx, sy, sz}] = s
+		return nil
+	}
+	s.data = append([]uint64(nil), data...)
+	for i := 0; i < sectionVolume; i++ {
+		v := s.read(i)
+		if s.direct {
+			if !w.known(v) {
+				return fmt.Errorf("section data references unknown state id %d", v)
+			}
+		} else if v >= len(s.palette) {
+			return fmt.Errorf("section data references palette index %d of %d", v, len(s.palette))
+		}
+	}
+	w.sections[[3]int{sx, sy, sz}] = s
+	return nil
+}
+
+// known reports whether id can be stored: any ID in the table, plus 0 for air.
+func (w *World) known(id int) bool {
+	_, ok := w.states.Lookup(id)
+	return ok || id == 0
+}
+
+// sectionIndex is the YZX index the game uses inside a section.
+func sectionIndex(x, y, z int) int {
+	return (y&15)<<8 | (z&15)<<4 | (x & 15)
+}
+
+// section is a paletted container of 4096 state IDs. Entries are packed
+// into uint64s without spanning word boundaries.
+type section struct {
+	bits       uint
+	direct     bool
+	globalBits uint
+	palette    []int
+	data       []uint64
+}
+
+func newSection(fill int, globalBits uint) *section {
+	return &section{palette: []int{fill}, globalBits: globalBits}
+}
+
+func (s *section) read(i int) int {
+	perLong := 64 / int(s.bits)
+	word := s.data[i/perLong]
+	shift := uint(i%perLong) * s.bits
+	return int((word >> shift) & (1<<s.bits - 1))
+}
+
+func (s *section) write(i, v int) {
+	perLong := 64 / int(s.bits)
+	shift := uint(i%perLong) * s.bits
+	mask := uint64(1<<s.bits-1) << shift
+	s.data[i/perLong] = s.data[i/perLong]&^mask | uint64(v)<<shift
+}
+
+func (s *section) get(i int) int {
+	if s.bits == 0 {
+		return s.palette[0]
+	}
+	v := s.read(i)
+	if s.direct {
+		return v
+	}
+	return s.palette[v]
+}
+
+func (s *section) set(i, id int) {
+	if s.direct {
+		s.write(i, id)
+		return
+	}
+	idx := -1
+	for j, p := range s.palette {
+		if p == id {
+			idx = j
+			break
+		}
+	}
+	if idx < 0 {
+		s.palette = append(s.palette, id)
+		idx = len(s.palette) - 1
+		if len(s.palette) > 1<<s.bits {
+			s.grow()
+			if s.direct {
+				s.write(i, id)
+				return
+			}
+		}
+	}
+	if s.bits == 0 {
+		return
+	}
+	s.write(i, idx)
+}
+
+// grow repacks the section with more bits per entry, switching to direct
+// global IDs once the palette no longer fits in maxIndirectBit bits.
+func (s *section) grow() {
+	values := make([]int, sectionVolume)
+	for i := range values {
+		values[i] = s.get(i)
+	}
+
+	need := uint(bits.Len(uint(len(s.palette) - 1)))
+	if need < minIndirectBit {
+		need = minIndirectBit
+	}
+	if need > maxIndirectBit {
+		s.direct = true
+		s.palette = nil
+		need = s.globalBits
+	}
+	s.bits = need
+	perLong := 64 / int(need)
+	s.data = make([]uint64, (sectionVolume+perLong-1)/perLong)
+
+	index := make(map[int]int, len(s.palette))
+	for j, p := range s.palette {
+		index[p] = j
+	}
+	for i, v := range values {
+		if s.direct {
+			s.write(i, v)
+		} else {
+			s.write(i, index[v])
+		}
+	}
+}
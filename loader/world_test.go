@@ -0,0 +1,159 @@
+package loader
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// syntheticTable returns a table with n states, IDs 0..n-1.
+func syntheticTable(t *testing.T, n int) *StateTable {
+	t.Helper()
+	blocks := make(map[StateKey]ShapeInfo, n)
+	for i := 0; i < n; i++ {
+		blocks[StateKey{BlockID: fmt.Sprintf("test:block_%d", i)}] = ShapeInfo{StateID: i}
+	}
+	table, err := NewStateTable(blocks)
+	if err != nil {
+		t.Fatalf("NewStateTable error: %v", err)
+	}
+	return table
+}
+
+func TestWorldSetAndGet(t *testing.T) {
+	table, err := LoadStateTableDir(filepath.Join("testdata", "blocks"))
+	if err != nil {
+		t.Fatalf("LoadStateTableDir error: %v", err)
+	}
+	w := NewWorld(table)
+
+	if id := w.StateIDAt(3, 64, -7); id != 0 {
+		t.Fatalf("expected unloaded position to read 0, got %d", id)
+	}
+
+	stone := StateKey{BlockID: "minecraft:stone"}
+	if err := w.SetState(-1, -1, -1, stone); err != nil {
+		t.Fatalf("SetState error: %v", err)
+	}
+	if err := w.SetStateID(15, 0, 0, 10); err != nil {
+		t.Fatalf("SetStateID error: %v", err)
+	}
+
+	if info := w.ShapeAt(-1, -1, -1); !info.SolidBlock || info.StateID != 1 {
+		t.Fatalf("expected stone at (-1,-1,-1), got %+v", info)
+	}
+	if id := w.StateIDAt(15, 0, 0); id != 10 {
+		t.Fatalf("expected id 10 at (15,0,0), got %d", id)
+	}
+	// Neighbours in the same section are untouched.
+	if id := w.StateIDAt(-2, -1, -1); id != 0 {
+		t.Fatalf("expected air next to stone, got %d", id)
+	}
+
+	if err := w.SetStateID(-1, -1, -1, 0); err != nil {
+		t.Fatalf("clearing to air: %v", err)
+	}
+	if id := w.StateIDAt(-1, -1, -1); id != 0 {
+		t.Fatalf("expected cleared position to read 0, got %d", id)
+	}
+
+	if err := w.SetStateID(0, 0, 0, 5); err == nil {
+		t.Fatalf("expected error for unknown state id")
+	}
+	if err := w.SetState(0, 0, 0, StateKey{BlockID: "minecraft:nope"}); err == nil {
+		t.Fatalf("expected error for unknown state key")
+	}
+}
+
+func TestWorldPaletteGrowth(t *testing.T) {
+	table := syntheticTable(t, 600)
+	w := NewWorld(table)
+
+	// Fill one section with more distinct states than an indirect palette
+	// can hold so it goes through every width and ends up direct.
+	for i := 0; i < sectionVolume; i++ {
+		x, y, z := i&15, i>>8, (i>>4)&15
+		if err := w.SetStateID(x, y, z, i%599+1); err != nil {
+			t.Fatalf("SetStateID error: %v", err)
+		}
+	}
+	s := w.sections[[3]int{0, 0, 0}]
+	if !s.direct || s.bits != 10 {
+		t.Fatalf("expected direct section with 10 bits, got direct=%v bits=%d", s.direct, s.bits)
+	}
+	for i := 0; i < sectionVolume; i++ {
+		x, y, z := i&15, i>>8, (i>>4)&15
+		if id := w.StateIDAt(x, y, z); id != i%599+1 {
+			t.Fatalf("at %d: expected %d, got %d", i, i%599+1, id)
+		}
+	}
+}
+
+func TestWorldPaletteWidths(t *testing.T) {
+	table := syntheticTable(t, 64)
+	w := NewWorld(table)
+
+	// 15 states plus the initial air entry fill a 4-bit palette.
+	for id := 1; id <= 15; id++ {
+		if err := w.SetStateID(id, 0, 0, id); err != nil {
+			t.Fatalf("SetStateID error: %v", err)
+		}
+	}
+	s := w.sections[[3]int{0, 0, 0}]
+	if s.direct || s.bits != 4 || len(s.palette) != 16 {
+		t.Fatalf("expected full 4-bit palette, got direct=%v bits=%d palette=%v", s.direct, s.bits, s.palette)
+	}
+
+	// One more forces a resize to 5 bits without losing existing entries.
+	if err := w.SetStateID(0, 1, 0, 16); err != nil {
+		t.Fatalf("SetStateID error: %v", err)
+	}
+	if s.direct || s.bits != 5 || len(s.palette) != 17 {
+		t.Fatalf("expected 5-bit palette, got direct=%v bits=%d palette=%v", s.direct, s.bits, s.palette)
+	}
+	for id := 1; id <= 15; id++ {
+		if got := w.StateIDAt(id, 0, 0); got != id {
+			t.Fatalf("expected %d at x=%d, got %d", id, id, got)
+		}
+	}
+	if got := w.StateIDAt(0, 1, 0); got != 16 {
+		t.Fatalf("expected 16 at (0,1,0), got %d", got)
+	}
+}
+
+func TestWorldLoadSection(t *testing.T) {
+	table := syntheticTable(t, 64)
+	w := NewWorld(table)
+
+	if err := w.LoadSection(0, -4, 0, 0, []int{7}, nil); err != nil {
+		t.Fatalf("single-valued LoadSection error: %v", err)
+	}
+	if id := w.StateIDAt(5, -60, 9); id != 7 {
+		t.Fatalf("expected 7 in single-valued section, got %d", id)
+	}
+
+	// 4 bits per entry, 16 entries per long: index i holds palette[i%2].
+	data := make([]uint64, 256)
+	for i := range data {
+		data[i] = 0x1010101010101010
+	}
+	if err := w.LoadSection(0, 0, 0, 4, []int{3, 9}, data); err != nil {
+		t.Fatalf("indirect LoadSection error: %v", err)
+	}
+	if id := w.StateIDAt(0, 0, 0); id != 3 {
+		t.Fatalf("expected 3 at even index, got %d", id)
+	}
+	if id := w.StateIDAt(1, 0, 0); id != 9 {
+		t.Fatalf("expected 9 at odd index, got %d", id)
+	}
+
+	if err := w.LoadSection(0, 0, 0, 4, []int{3, 9}, data[:10]); err == nil {
+		t.Fatalf("expected error for short data")
+	}
+	if err := w.LoadSection(0, 0, 0, 4, []int{3}, data); err == nil {
+		t.Fatalf("expected error for out-of-range palette index")
+	}
+	if err := w.LoadSection(0, 0, 0, 0, []int{99}, nil); err == nil {
+		t.Fatalf("expected error for unknown palette id")
+	}
+}